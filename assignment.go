@@ -1,5 +1,28 @@
 package grades
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// timeLayouts is the layouts that ParseTime accepts: RFC 3339 and the layout of
+// Gradescope's exports.
+var timeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05 -0700"}
+
+// ParseTime parses a time such as an assignment's release or due time, written
+// in RFC 3339 like "2021-10-01T17:00:00-07:00" or in the layout of
+// Gradescope's exports like "2021-10-01 17:00:00 -0700".
+func ParseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("Invalid time %q; expected a time like %s", value, time.RFC3339)
+}
+
 // Assignment is an assignment that students submit work for.
 type Assignment struct {
 	// Name is the name of the assignment.
@@ -22,10 +45,26 @@ type Assignment struct {
 	// applied to this assignment.
 	SlipGroup int
 
+	// Released is when the assignment was released, or the zero time if
+	// unknown.
+	Released time.Time
+
+	// Due is when the assignment was due, or the zero time if unknown.
+	Due time.Time
+
 	// Grade is the submission present on the assignment.
 	Grade AssignmentSubmission
 }
 
+// Window returns how long students had to work on the assignment, from its
+// release to its deadline, or 0 if either is unknown.
+func (a *Assignment) Window() time.Duration {
+	if a.Released.IsZero() || a.Due.IsZero() {
+		return 0
+	}
+	return a.Due.Sub(a.Released)
+}
+
 // Clone returns a copy of the assignment.
 func (a *Assignment) Clone() *Assignment {
 	newAsssignment := *a
@@ -4,12 +4,35 @@ import (
 	"time"
 )
 
+// SubmissionStatus is the grading status of a submission.
+type SubmissionStatus int
+
+const (
+	// StatusGraded is a submission that has been graded.
+	StatusGraded SubmissionStatus = iota
+
+	// StatusUngraded is a submission that was turned in but has not yet been
+	// graded.
+	StatusUngraded
+
+	// StatusMissing is a submission that was never turned in.
+	StatusMissing
+
+	// StatusPending is a submission that the student will complete after the
+	// term under an Incomplete grade. Pending submissions are left out of
+	// category scores until they are graded.
+	StatusPending
+)
+
 // AssignmentSubmission describes a student's graded submission to an
 // Assignment.
 type AssignmentSubmission struct {
 	// Score is the raw score on the submission.
 	Score float64
 
+	// Status is the grading status of the submission.
+	Status SubmissionStatus
+
 	// Lateness is the amount of time after the deadline that the submission
 	// was posted.
 	Lateness time.Duration
@@ -0,0 +1,404 @@
+package grades
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// cacheVersion is the version of the cache's hashing and file format. Cache
+// files with a different version are ignored.
+const cacheVersion = 6
+
+// submissionRecord is the cached form of an AssignmentSubmission, including
+// its unexported fields.
+type submissionRecord struct {
+	Score              float64          `json:"score"`
+	Status             SubmissionStatus `json:"status"`
+	Lateness           time.Duration    `json:"lateness"`
+	SlipDaysApplied    int              `json:"slip_days_applied"`
+	MultipliersApplied []Multiplier     `json:"multipliers_applied"`
+	Dropped            bool             `json:"dropped"`
+	HasOverride        bool             `json:"has_override"`
+	Override           float64          `json:"override"`
+	Comments           []string         `json:"comments"`
+}
+
+// assignmentRecord is the cached form of an Assignment.
+type assignmentRecord struct {
+	Name         string           `json:"name"`
+	CategoryName string           `json:"category_name"`
+	MaxScore     float64          `json:"max_score"`
+	Weight       float64          `json:"weight"`
+	SlipGroup    int              `json:"slip_group"`
+	Released     time.Time        `json:"released"`
+	Due          time.Time        `json:"due"`
+	Grade        submissionRecord `json:"grade"`
+}
+
+// categoryRecord is the cached form of a Category, including its unexported
+// fields.
+type categoryRecord struct {
+	Name              string   `json:"name"`
+	Weight            float64  `json:"weight"`
+	Drops             int      `json:"drops"`
+	SlipDays          int      `json:"slip_days"`
+	HasLateMultiplier bool     `json:"has_late_multiplier"`
+	HasOverride       bool     `json:"has_override"`
+	Override          float64  `json:"override"`
+	Comments          []string `json:"comments"`
+}
+
+// studentRecord is the cached form of a Student. Every field that can affect
+// a student's grade must be recorded so that it is part of the student's
+// hash.
+type studentRecord struct {
+	SID          int                          `json:"sid"`
+	Name         string                       `json:"name"`
+	Email        string                       `json:"email"`
+	Units        float64                      `json:"units"`
+	GradingBasis GradingBasis                 `json:"grading_basis"`
+	SlipDaysUsed int                          `json:"slip_days_used"`
+	Categories   map[string]*categoryRecord   `json:"categories"`
+	Assignments  map[string]*assignmentRecord `json:"assignments"`
+	Comments     []string                     `json:"comments"`
+}
+
+// reportRecord is the cached form of a GradeReport.
+type reportRecord struct {
+	Student     *studentRecord               `json:"student"`
+	TotalScore  float64                      `json:"total"`
+	Letter      string                       `json:"letter"`
+	Categories  map[string]*ReportCategory   `json:"categories"`
+	Assignments map[string]*ReportAssignment `json:"assignments"`
+	Comments    []string                     `json:"comments"`
+	Pending     []string                     `json:"pending"`
+}
+
+func newStudentRecord(student *Student) *studentRecord {
+	record := &studentRecord{
+		SID:          student.SID,
+		Name:         student.Name,
+		Email:        student.Email,
+		Units:        student.Units,
+		GradingBasis: student.GradingBasis,
+		SlipDaysUsed: student.SlipDaysUsed,
+		Categories:   make(map[string]*categoryRecord, len(student.Categories)),
+		Assignments:  make(map[string]*assignmentRecord, len(student.Assignments)),
+		Comments:     student.Comments,
+	}
+	for name, category := range student.Categories {
+		record.Categories[name] = &categoryRecord{
+			Name:              category.Name,
+			Weight:            category.Weight,
+			Drops:             category.Drops,
+			SlipDays:          category.SlipDays,
+			HasLateMultiplier: category.HasLateMultiplier,
+			HasOverride:       category.hasOverride,
+			Override:          category.override,
+			Comments:          category.Comments,
+		}
+	}
+	for name, assignment := range student.Assignments {
+		grade := assignment.Grade
+		record.Assignments[name] = &assignmentRecord{
+			Name:         assignment.Name,
+			CategoryName: assignment.CategoryName,
+			MaxScore:     assignment.MaxScore,
+			Weight:       assignment.Weight,
+			SlipGroup:    assignment.SlipGroup,
+			Released:     assignment.Released,
+			Due:          assignment.Due,
+			Grade: submissionRecord{
+				Score:              grade.Score,
+				Status:             grade.Status,
+				Lateness:           grade.Lateness,
+				SlipDaysApplied:    grade.SlipDaysApplied,
+				MultipliersApplied: grade.MultipliersApplied,
+				Dropped:            grade.Dropped,
+				HasOverride:        grade.hasOverride,
+				Override:           grade.override,
+				Comments:           grade.Comments,
+			},
+		}
+	}
+	return record
+}
+
+func (record *studentRecord) student() *Student {
+	student := &Student{
+		SID:          record.SID,
+		Name:         record.Name,
+		Email:        record.Email,
+		Units:        record.Units,
+		GradingBasis: record.GradingBasis,
+		SlipDaysUsed: record.SlipDaysUsed,
+		Categories:   make(map[string]*Category, len(record.Categories)),
+		Assignments:  make(map[string]*Assignment, len(record.Assignments)),
+		Comments:     record.Comments,
+	}
+	for name, category := range record.Categories {
+		student.Categories[name] = &Category{
+			Name:              category.Name,
+			Weight:            category.Weight,
+			Drops:             category.Drops,
+			SlipDays:          category.SlipDays,
+			HasLateMultiplier: category.HasLateMultiplier,
+			hasOverride:       category.HasOverride,
+			override:          category.Override,
+			Comments:          category.Comments,
+		}
+	}
+	for name, assignment := range record.Assignments {
+		grade := assignment.Grade
+		student.Assignments[name] = &Assignment{
+			Name:         assignment.Name,
+			CategoryName: assignment.CategoryName,
+			MaxScore:     assignment.MaxScore,
+			Weight:       assignment.Weight,
+			SlipGroup:    assignment.SlipGroup,
+			Released:     assignment.Released,
+			Due:          assignment.Due,
+			Grade: AssignmentSubmission{
+				Score:              grade.Score,
+				Status:             grade.Status,
+				Lateness:           grade.Lateness,
+				SlipDaysApplied:    grade.SlipDaysApplied,
+				MultipliersApplied: grade.MultipliersApplied,
+				Dropped:            grade.Dropped,
+				hasOverride:        grade.HasOverride,
+				override:           grade.Override,
+				Comments:           grade.Comments,
+			},
+		}
+	}
+	return student
+}
+
+// Hash returns a hash of everything about the student that can affect their
+// grade, including overrides.
+func (student *Student) Hash() string {
+	// Maps are encoded with sorted keys, so the encoding is deterministic.
+	encoded, err := json.Marshal(newStudentRecord(student))
+	if err != nil {
+		panic(err)
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:])
+}
+
+// cacheFile is the format of a cache file.
+type cacheFile struct {
+	Version int                        `json:"version"`
+	Entries map[string]json.RawMessage `json:"entries"`
+}
+
+// Cache is a cache of finalized grade reports keyed by a hash of each
+// student's inputs and the pipeline, so that only students whose inputs
+// changed are recomputed. A stage whose parameters are keyed by SID only
+// contributes the student's own parameters to their key, so that changing
+// one student's parameters does not recompute everyone. If the pipeline
+// contains a policy that depends on the whole roster, the whole pipeline and
+// the hash of every student are part of every key.
+type Cache struct {
+	// entries is the encoded reportRecord of each key.
+	entries map[string]json.RawMessage
+
+	// used is the keys used by the last call to Finalize.
+	used map[string]bool
+
+	// Hits is the number of students whose reports were cached in the last
+	// call to Finalize.
+	Hits int
+
+	// Misses is the number of students whose reports were computed in the
+	// last call to Finalize.
+	Misses int
+}
+
+// NewCache returns an empty cache.
+func NewCache() *Cache {
+	return &Cache{
+		entries: make(map[string]json.RawMessage),
+		used:    make(map[string]bool),
+	}
+}
+
+// LoadCache loads the cache file at the given path. A missing file, or a file
+// written by an incompatible version, results in an empty cache.
+func LoadCache(path string) (*Cache, error) {
+	cache := NewCache()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cache, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var file cacheFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("Invalid cache file %s: %w", path, err)
+	}
+	if file.Version == cacheVersion && file.Entries != nil {
+		cache.entries = file.Entries
+	}
+	return cache, nil
+}
+
+// Save writes the entries used by the last call to Finalize to the cache file
+// at the given path. Stale entries are discarded.
+func (cache *Cache) Save(path string) error {
+	file := cacheFile{
+		Version: cacheVersion,
+		Entries: make(map[string]json.RawMessage, len(cache.used)),
+	}
+	for key := range cache.used {
+		file.Entries[key] = cache.entries[key]
+	}
+	data, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Finalize applies the pipeline to the roster and finalizes it like
+// Roster.Finalize, reusing cached reports for students whose inputs and
+// pipeline have not changed.
+func (cache *Cache) Finalize(pipeline Pipeline, roster Roster) (map[int]*GradeReport, error) {
+	if err := pipeline.Validate(); err != nil {
+		return nil, err
+	}
+	dependsOnRoster := pipeline.DependsOnRoster()
+	encodedPipeline, err := json.Marshal(pipeline)
+	if err != nil {
+		return nil, err
+	}
+	stageParams := make([]map[int]json.RawMessage, len(pipeline))
+	if !dependsOnRoster {
+		for i, stage := range pipeline {
+			stageParams[i] = paramsBySID(stage.Params)
+		}
+	}
+
+	keys := make([]int, 0, len(roster))
+	for key := range roster {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+
+	// Hash each key's outcomes, along with the roster if needed.
+	outcomeHashes := make(map[int][]string, len(roster))
+	rosterHash := sha256.New()
+	for _, key := range keys {
+		hashes := make([]string, len(roster[key]))
+		for i, outcome := range roster[key] {
+			hashes[i] = outcome.Hash()
+		}
+		outcomeHashes[key] = hashes
+		fmt.Fprintf(rosterHash, "%d:%v\n", key, hashes)
+	}
+	cacheKeys := make(map[int]string, len(roster))
+	for _, key := range keys {
+		hash := sha256.New()
+		fmt.Fprintf(hash, "%d\n", cacheVersion)
+		if dependsOnRoster {
+			fmt.Fprintf(hash, "%s\n", encodedPipeline)
+		} else {
+			for i, stage := range pipeline {
+				params := stage.Params
+				if stageParams[i] != nil {
+					params = stageParams[i][key]
+				}
+				fmt.Fprintf(hash, "%q %s\n", stage.Name, params)
+			}
+		}
+		fmt.Fprintf(hash, "%d:%v\n", key, outcomeHashes[key])
+		if dependsOnRoster {
+			hash.Write(rosterHash.Sum(nil))
+		}
+		cacheKeys[key] = hex.EncodeToString(hash.Sum(nil))
+	}
+
+	// Decode cached reports and compute the rest.
+	cache.used = make(map[string]bool, len(roster))
+	cache.Hits, cache.Misses = 0, 0
+	reports := make(map[int]*GradeReport, len(roster))
+	misses := make(Roster)
+	for _, key := range keys {
+		cacheKey := cacheKeys[key]
+		cache.used[cacheKey] = true
+		if encoded, ok := cache.entries[cacheKey]; ok {
+			var record reportRecord
+			if err := json.Unmarshal(encoded, &record); err == nil && record.Student != nil {
+				reports[key] = &GradeReport{
+					Student:     record.Student.student(),
+					TotalScore:  record.TotalScore,
+					Letter:      record.Letter,
+					Categories:  record.Categories,
+					Assignments: record.Assignments,
+					Comments:    record.Comments,
+					Pending:     record.Pending,
+				}
+				continue
+			}
+		}
+		misses[key] = roster[key]
+	}
+	if len(misses) == 0 {
+		cache.Hits = len(roster)
+		return reports, nil
+	}
+
+	// Policies that depend on the roster must see every student, and every
+	// key misses when the roster changes anyway.
+	if dependsOnRoster {
+		misses = roster
+	}
+	applied, err := pipeline.Apply(misses)
+	if err != nil {
+		return nil, err
+	}
+	for key, report := range applied.Finalize() {
+		encoded, err := json.Marshal(&reportRecord{
+			Student:     newStudentRecord(report.Student),
+			TotalScore:  report.TotalScore,
+			Letter:      report.Letter,
+			Categories:  report.Categories,
+			Assignments: report.Assignments,
+			Comments:    report.Comments,
+			Pending:     report.Pending,
+		})
+		if err != nil {
+			return nil, err
+		}
+		cache.entries[cacheKeys[key]] = encoded
+		reports[key] = report
+		cache.Misses++
+	}
+	cache.Hits = len(roster) - cache.Misses
+	return reports, nil
+}
+
+// paramsBySID returns the parameters split by SID if they are a JSON object
+// whose keys are all SIDs, as in the parameters of policies that apply
+// per-student changes, or nil otherwise.
+func paramsBySID(params json.RawMessage) map[int]json.RawMessage {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(params, &object); err != nil || object == nil {
+		return nil
+	}
+	bySID := make(map[int]json.RawMessage, len(object))
+	for key, value := range object {
+		sid, err := strconv.Atoi(key)
+		if err != nil {
+			return nil
+		}
+		bySID[sid] = value
+	}
+	return bySID
+}
@@ -0,0 +1,114 @@
+package grades
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+func newCacheTestStudent(sid int, score float64) *Student {
+	return &Student{
+		SID: sid,
+		Categories: map[string]*Category{
+			"Homework": {Name: "Homework", Weight: 1},
+		},
+		Assignments: map[string]*Assignment{
+			"HW 1": {
+				Name:         "HW 1",
+				CategoryName: "Homework",
+				MaxScore:     10,
+				Weight:       1,
+				Grade:        AssignmentSubmission{Score: score, Status: StatusGraded},
+			},
+		},
+	}
+}
+
+func TestStudentHash(t *testing.T) {
+	student := newCacheTestStudent(1, 8)
+	hash := student.Hash()
+	if newCacheTestStudent(1, 8).Hash() != hash {
+		t.Error("Equal students have different hashes")
+	}
+	student.Assignments["HW 1"].Grade.SetOverride(1)
+	if student.Hash() == hash {
+		t.Error("Override did not change the student's hash")
+	}
+}
+
+func TestCacheFinalize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	pipeline := Pipeline{{Name: "test-first"}, {Name: "test-second"}}
+	roster := Roster{
+		1: {newCacheTestStudent(1, 8)},
+		2: {newCacheTestStudent(2, 9)},
+		3: {newCacheTestStudent(3, 10)},
+	}
+
+	cache, err := LoadCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.Finalize(pipeline, roster); err != nil {
+		t.Fatal(err)
+	}
+	if cache.Hits != 0 || cache.Misses != 3 {
+		t.Errorf("First run had %d hits and %d misses; expected 0 and 3", cache.Hits, cache.Misses)
+	}
+	if err := cache.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	roster[2] = []*Student{newCacheTestStudent(2, 5)}
+	cache, err = LoadCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reports, err := cache.Finalize(pipeline, roster)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cache.Hits != 2 || cache.Misses != 1 {
+		t.Errorf("Second run had %d hits and %d misses; expected 2 and 1", cache.Hits, cache.Misses)
+	}
+	if _, err := cache.Finalize(pipeline, roster); err != nil {
+		t.Fatal(err)
+	}
+	if cache.Hits != 3 || cache.Misses != 0 {
+		t.Errorf("Third run had %d hits and %d misses; expected 3 and 0", cache.Hits, cache.Misses)
+	}
+	expected := roster.Finalize()
+	for sid, report := range reports {
+		if report.TotalScore != expected[sid].TotalScore {
+			t.Errorf("SID %d has total %v; expected %v", sid, report.TotalScore, expected[sid].TotalScore)
+		}
+	}
+}
+
+func TestCacheFinalizeParamsBySID(t *testing.T) {
+	roster := Roster{
+		1: {newCacheTestStudent(1, 8)},
+		2: {newCacheTestStudent(2, 9)},
+		3: {newCacheTestStudent(3, 10)},
+	}
+	cache := NewCache()
+	run := func(params string) (int, int) {
+		pipeline := Pipeline{{Name: "test-first", Params: json.RawMessage(params)}}
+		if _, err := cache.Finalize(pipeline, roster); err != nil {
+			t.Fatal(err)
+		}
+		return cache.Hits, cache.Misses
+	}
+
+	run(`{"1": 5, "2": 6}`)
+	if hits, misses := run(`{"1": 5, "2": 7}`); hits != 2 || misses != 1 {
+		t.Errorf("Changing one student's parameters had %d hits and %d misses; expected 2 and 1", hits, misses)
+	}
+	if hits, misses := run(`{"1": 5, "2": 7, "3": 1}`); hits != 2 || misses != 1 {
+		t.Errorf("Adding one student's parameters had %d hits and %d misses; expected 2 and 1", hits, misses)
+	}
+	run(`{"scale": [1]}`)
+	if hits, misses := run(`{"scale": [0.9]}`); hits != 0 || misses != 3 {
+		t.Errorf("Changing parameters not keyed by SID had %d hits and %d misses; expected 0 and 3", hits, misses)
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cs161-staff/grades"
+	"github.com/cs161-staff/grades/policies/extensions"
+)
+
+// accommodation is a student's accommodations in one category, such as those
+// granted by a DSP letter.
+type accommodation struct {
+	SID      int
+	Category string
+
+	// Drops and SlipDays are the extra drops and slip days in the category.
+	Drops    int
+	SlipDays int
+
+	// ExtensionPercent is the extension on the deadline of every assignment
+	// in the category, as a percentage of the assignment's window.
+	ExtensionPercent float64
+
+	Note string
+}
+
+// String describes the accommodation for the student's comments.
+func (a *accommodation) String() string {
+	changes := make([]string, 0, 3)
+	if a.Drops != 0 {
+		changes = append(changes, fmt.Sprintf("%+d drops", a.Drops))
+	}
+	if a.SlipDays != 0 {
+		changes = append(changes, fmt.Sprintf("%+d slip days", a.SlipDays))
+	}
+	if a.ExtensionPercent != 0.0 {
+		changes = append(changes, fmt.Sprintf("%+g%% time", a.ExtensionPercent))
+	}
+	description := fmt.Sprintf("Accommodation in %s: %s", a.Category, strings.Join(changes, ", "))
+	if a.Note != "" {
+		description += " (" + a.Note + ")"
+	}
+	return description
+}
+
+// parseOptionalInt parses an integer, returning 0 if the value is empty.
+func parseOptionalInt(value string) (int, error) {
+	if value = strings.TrimSpace(value); value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// importAccommodations imports the CSV of accommodations at the given path,
+// with SID, Category, Extra Drops, Extra Slip Days, Extension and Note
+// columns and optional Email and Name columns. The Extension column is a
+// percentage of each assignment's window, like "50%". Empty cells mean no
+// accommodation, and negative values are rejected. Rows are matched to
+// students with the resolver.
+func importAccommodations(path string, categories map[string]*grades.Category, resolver *identityResolver) []*accommodation {
+	reader, err := NewDictReaderFromPath(path)
+	panicIfErr(err)
+
+	accommodations := make([]*accommodation, 0)
+	for row, err := reader.Read(); err != io.EOF; row, err = reader.Read() {
+		panicIfErr(err)
+
+		a := &accommodation{
+			Category: strings.TrimSpace(row["Category"]),
+			Note:     strings.TrimSpace(row["Note"]),
+		}
+		if _, ok := categories[a.Category]; !ok {
+			panic(fmt.Errorf("Unknown category in accommodations for SID %s: %s", row["SID"], a.Category))
+		}
+		a.Drops, err = parseOptionalInt(row["Extra Drops"])
+		panicIfErr(err)
+		a.SlipDays, err = parseOptionalInt(row["Extra Slip Days"])
+		panicIfErr(err)
+		if extension := strings.TrimSuffix(strings.TrimSpace(row["Extension"]), "%"); extension != "" {
+			a.ExtensionPercent, err = strconv.ParseFloat(extension, 64)
+			panicIfErr(err)
+		}
+		if a.Drops < 0 || a.SlipDays < 0 || a.ExtensionPercent < 0.0 {
+			panic(fmt.Errorf("Negative accommodation for SID %s in category %s", row["SID"], a.Category))
+		}
+		if a.SID = resolver.ResolveRow("Accommodations", row); a.SID == 0 {
+			continue
+		}
+		accommodations = append(accommodations, a)
+	}
+	return accommodations
+}
+
+// applyAccommodations inserts stages into the course's pipeline that apply
+// the accommodations with the changedrops, changeslipdays and extensions
+// policies, notes each accommodation in the student's comments and reports
+// every accommodation applied. Students who are not on the roster are reported
+// and skipped. Extensions only apply to assignments with release and due
+// times; other assignments in the category are reported once and skipped.
+func applyAccommodations(course *grades.CourseConfig, roster grades.Roster, accommodations []*accommodation) {
+	sort.SliceStable(accommodations, func(i, j int) bool {
+		return accommodations[i].SID < accommodations[j].SID
+	})
+	withoutWindow := make(map[string]bool)
+
+	drops := make(map[int]map[string]int)
+	slipDays := make(map[int]map[string]int)
+	extensionParams := make(map[int]map[string]extensions.Extension)
+	add := func(params map[int]map[string]int, sid int, name string, change int) {
+		if change == 0 {
+			return
+		}
+		if _, ok := params[sid]; !ok {
+			params[sid] = make(map[string]int)
+		}
+		params[sid][name] += change
+	}
+	applied := 0
+	for _, a := range accommodations {
+		outcomes, ok := roster[a.SID]
+		if !ok {
+			warn("SID %d in accommodations is not on the roster", a.SID)
+			continue
+		}
+		add(drops, a.SID, a.Category, a.Drops)
+		add(slipDays, a.SID, a.Category, a.SlipDays)
+		if a.ExtensionPercent != 0.0 {
+			if _, ok := extensionParams[a.SID]; !ok {
+				extensionParams[a.SID] = make(map[string]extensions.Extension)
+			}
+			for _, name := range outcomes[0].AssignmentNames() {
+				assignment := course.Assignments[name]
+				if assignment.CategoryName != a.Category {
+					continue
+				}
+				if assignment.Window() == 0 {
+					if !withoutWindow[name] {
+						warn("Accommodation extensions skip assignment %s, which has no release and due times", name)
+						withoutWindow[name] = true
+					}
+					continue
+				}
+				extension := extensionParams[a.SID][name]
+				extension.Percent += a.ExtensionPercent
+				extensionParams[a.SID][name] = extension
+			}
+		}
+		for _, student := range outcomes {
+			student.Comments = append(student.Comments, a.String())
+		}
+		note("SID %d (%s): %s", a.SID, outcomes[0].Name, a)
+		applied++
+	}
+	note("Applied %d accommodations", applied)
+
+	stages := make(grades.Pipeline, 0, 3)
+	addStage := func(policy string, params interface{}, empty bool) {
+		if empty {
+			return
+		}
+		encoded, err := json.Marshal(params)
+		panicIfErr(err)
+		stages = append(stages, grades.Stage{Name: policy, Params: encoded})
+	}
+	addStage("extensions", extensionParams, len(extensionParams) == 0)
+	addStage("changedrops", drops, len(drops) == 0)
+	addStage("changeslipdays", slipDays, len(slipDays) == 0)
+	pipeline, err := course.Pipeline.Insert(stages...)
+	panicIfErr(err)
+	course.Pipeline = pipeline
+}
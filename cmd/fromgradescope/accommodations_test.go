@@ -0,0 +1,95 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cs161-staff/grades"
+)
+
+func TestImportAccommodations(t *testing.T) {
+	categories := map[string]*grades.Category{"Homework": {Name: "Homework"}, "Exams": {Name: "Exams"}}
+	entries := []*rosterEntry{
+		{Student: &grades.Student{SID: 3031000001, Name: "Smith, Alice", Email: "alice@berkeley.edu"}},
+	}
+	resolver, err := newIdentityResolver(entries, []string{"sid", "email"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := writeTemp(t, "accommodations.csv", `SID,Email,Category,Extra Drops,Extra Slip Days,Extension,Note
+,alice@berkeley.edu,Homework,1,,50%,DSP
+3031000001,,Exams,,,,
+`)
+	accommodations := importAccommodations(path, categories, resolver)
+	if len(accommodations) != 2 {
+		t.Fatalf("Got %d accommodations; expected 2", len(accommodations))
+	}
+	if a := accommodations[0]; a.SID != 3031000001 || a.Drops != 1 || a.SlipDays != 0 || a.ExtensionPercent != 50 || a.Note != "DSP" {
+		t.Errorf("Got accommodation %+v", a)
+	}
+	if description := accommodations[0].String(); description != "Accommodation in Homework: +1 drops, +50% time (DSP)" {
+		t.Errorf("Got description %q", description)
+	}
+
+	for _, row := range []string{
+		"3031000001,,Quizzes,1,,,",
+		"3031000001,,Homework,-1,,,",
+		"3031000001,,Homework,,-2,,",
+		"3031000001,,Homework,,,-50%,",
+		"3031000001,,Homework,one,,,",
+	} {
+		expectPanic(t, "Accommodation "+row, func() {
+			importAccommodations(writeTemp(t, "accommodations.csv", "SID,Email,Category,Extra Drops,Extra Slip Days,Extension,Note\n"+row+"\n"), categories, resolver)
+		})
+	}
+}
+
+func TestApplyAccommodations(t *testing.T) {
+	released := time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+	categories := map[string]*grades.Category{"Homework": {Name: "Homework", Weight: 1}}
+	assignments := map[string]*grades.Assignment{
+		"HW 1": {Name: "HW 1", CategoryName: "Homework", MaxScore: 10, Weight: 1, Released: released, Due: released.Add(48 * time.Hour)},
+		"HW 2": {Name: "HW 2", CategoryName: "Homework", MaxScore: 10, Weight: 1},
+	}
+	course := &grades.CourseConfig{
+		Categories:  categories,
+		Assignments: assignments,
+		Pipeline:    grades.Pipeline{{Name: "slipdays"}, {Name: "drops"}},
+	}
+	roster := grades.Roster{
+		1: {grades.NewStudent(1, "Alice", categories, assignments, nil)},
+		2: {grades.NewStudent(2, "Bob", categories, assignments, nil)},
+	}
+	applyAccommodations(course, roster, []*accommodation{
+		{SID: 1, Category: "Homework", Drops: 1, SlipDays: 2, ExtensionPercent: 50},
+		// Students without an extension do not need release and due times.
+		{SID: 2, Category: "Homework", Drops: 1},
+		{SID: 3, Category: "Homework", Drops: 1},
+	})
+
+	expected := []struct {
+		name   string
+		params string
+	}{
+		{"extensions", `{"1":{"HW 1":"50%"}}`},
+		{"changedrops", `{"1":{"Homework":1},"2":{"Homework":1}}`},
+		{"changeslipdays", `{"1":{"Homework":2}}`},
+		{"slipdays", ""},
+		{"drops", ""},
+	}
+	if len(course.Pipeline) != len(expected) {
+		t.Fatalf("Got pipeline %+v", course.Pipeline)
+	}
+	for i, stage := range course.Pipeline {
+		if stage.Name != expected[i].name || string(stage.Params) != expected[i].params {
+			t.Errorf("Got stage %s with parameters %s; expected %s with %s", stage.Name, stage.Params, expected[i].name, expected[i].params)
+		}
+	}
+	if comments := roster[1][0].Comments; len(comments) != 1 {
+		t.Errorf("Got comments %v for a student with an accommodation", comments)
+	}
+	if _, err := course.Pipeline.Apply(roster); err != nil {
+		t.Error(err)
+	}
+}
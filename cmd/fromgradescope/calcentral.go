@@ -0,0 +1,181 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/cs161-staff/grades"
+)
+
+// Column names used in the CalCentral roster export.
+const (
+	calcentralSID          = "Student ID"
+	calcentralName         = "Name"
+	calcentralEmail        = "Email Address"
+	calcentralUnits        = "Units"
+	calcentralGradingBasis = "Grading Basis"
+	calcentralStatus       = "Enrollment Status"
+)
+
+// enrollmentStatus is a student's enrollment status in the course.
+type enrollmentStatus int
+
+const (
+	statusEnrolled enrollmentStatus = iota
+	statusWaitlisted
+	statusDropped
+)
+
+// rosterEntry is a student's row in the CalCentral roster.
+type rosterEntry struct {
+	// Student is the student described by the row, without any categories or
+	// assignments.
+	Student *grades.Student
+
+	// Status is the student's enrollment status.
+	Status enrollmentStatus
+}
+
+// importRoster imports and returns the students in the CalCentral roster at
+// the given path. Students who have dropped the course are excluded.
+func importRoster(path string) []*rosterEntry {
+	reader, err := NewDictReaderFromPath(path)
+	panicIfErr(err)
+
+	entries := make([]*rosterEntry, 0)
+	seen := make(map[int]bool)
+	for row, err := reader.Read(); err != io.EOF; row, err = reader.Read() {
+		panicIfErr(err)
+
+		sid, err := strconv.Atoi(strings.TrimSpace(row[calcentralSID]))
+		panicIfErr(err)
+		var units float64
+		if unitsValue := strings.TrimSpace(row[calcentralUnits]); unitsValue != "" {
+			units, err = strconv.ParseFloat(unitsValue, 64)
+			panicIfErr(err)
+		}
+		status, err := parseEnrollmentStatus(row[calcentralStatus])
+		panicIfErr(err)
+		if status == statusDropped {
+			continue
+		}
+		gradingBasis, err := grades.ParseGradingBasis(row[calcentralGradingBasis])
+		panicIfErr(err)
+		if seen[sid] {
+			panic(fmt.Errorf("Duplicate student specified in imported roster: %d", sid))
+		}
+		seen[sid] = true
+
+		entries = append(entries, &rosterEntry{
+			Student: &grades.Student{
+				SID:          sid,
+				Name:         strings.TrimSpace(row[calcentralName]),
+				Email:        strings.TrimSpace(row[calcentralEmail]),
+				Units:        units,
+				GradingBasis: gradingBasis,
+			},
+			Status: status,
+		})
+	}
+
+	return entries
+}
+
+// parseEnrollmentStatus parses an enrollment status from the roster. Rosters
+// without a status column are treated as only containing enrolled students.
+func parseEnrollmentStatus(value string) (enrollmentStatus, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "e", "enrolled":
+		return statusEnrolled, nil
+	case "w", "waitlisted":
+		return statusWaitlisted, nil
+	case "d", "dropped":
+		return statusDropped, nil
+	default:
+		return 0, errors.New("Unknown enrollment status " + value)
+	}
+}
+
+// reconcile matches the students in one of the data sources to the roster
+// using the identity resolver. It returns the index of the matched identity for
+// each roster SID that has one, and reports the students that only appear in
+// one of the sources and the ambiguous matches to stderr. If two identities
+// match the same student and only one of them matches exactly, by SID or by
+// hand, that one is matched and the other is left unmatched, such as for a
+// dropped student whose SID is one digit off from an enrolled student's.
+// Otherwise the first one is matched.
+func reconcile(source string, entries []*rosterEntry, ids []identity, resolver *identityResolver) map[int]int {
+	bySID := make(map[int]int, len(entries))
+	rules := make(map[int]string, len(entries))
+	unmatchedSource := make([]identity, 0)
+	ambiguous := make([]identity, 0)
+	for i, id := range ids {
+		match := resolver.Resolve(id)
+		if match.Ambiguous() {
+			ambiguous = append(ambiguous, id)
+			continue
+		}
+		if match.SID == 0 {
+			unmatchedSource = append(unmatchedSource, id)
+			continue
+		}
+		if other, ok := bySID[match.SID]; ok {
+			var exact, rejected int
+			switch {
+			case exactRule(match.Rule) && !exactRule(rules[match.SID]):
+				exact, rejected = i, other
+			case exactRule(rules[match.SID]) && !exactRule(match.Rule):
+				exact, rejected = other, i
+			default:
+				warn("%s and %s on %s both match SID %d", ids[other].Name, id.Name, source, match.SID)
+				continue
+			}
+			warn("%s on %s matches SID %d exactly, so %s is not matched to it", ids[exact].Name, source, match.SID, ids[rejected].Name)
+			resolver.Reject(ids[rejected])
+			unmatchedSource = append(unmatchedSource, ids[rejected])
+			if rejected == i {
+				continue
+			}
+		}
+		bySID[match.SID] = i
+		rules[match.SID] = match.Rule
+	}
+	unmatchedRoster := make([]*rosterEntry, 0)
+	for _, entry := range entries {
+		if _, ok := bySID[entry.Student.SID]; !ok {
+			unmatchedRoster = append(unmatchedRoster, entry)
+		}
+	}
+
+	if len(unmatchedSource) > 0 || len(unmatchedRoster) > 0 || len(ambiguous) > 0 {
+		fmt.Fprintf(warnings, "Reconciliation report for %s:\n", source)
+		if len(unmatchedSource) > 0 {
+			fmt.Fprintf(warnings, "  In %s but not on the roster:\n", source)
+			for _, id := range unmatchedSource {
+				fmt.Fprintf(warnings, "    %s (SID %q, %s)\n", id.Name, id.SID, id.Email)
+			}
+		}
+		if len(unmatchedRoster) > 0 {
+			fmt.Fprintf(warnings, "  On the roster but not in %s:\n", source)
+			for _, entry := range unmatchedRoster {
+				fmt.Fprintf(warnings, "    %s (SID %d, %s)\n", entry.Student.Name, entry.Student.SID, entry.Student.Email)
+			}
+		}
+		if len(ambiguous) > 0 {
+			fmt.Fprintln(warnings, "  Ambiguous matches to review in the mapping file:")
+			for _, id := range ambiguous {
+				fmt.Fprintf(warnings, "    %s (SID %q, %s)\n", id.Name, id.SID, id.Email)
+			}
+		}
+	}
+
+	return bySID
+}
+
+// exactRule returns whether matches by the rule with the given name are exact.
+func exactRule(name string) bool {
+	return name == "sid" || name == manualRule
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/cs161-staff/grades"
+)
+
+func TestImportRoster(t *testing.T) {
+	path := writeTemp(t, "roster.csv", `Name,Student ID,Email Address,Units,Grading Basis,Enrollment Status
+"Smith, Alice",3031000001,alice@berkeley.edu,4,EPN,Enrolled
+"Jones, Bob",3031000002,bob@berkeley.edu,4,,Waitlisted
+"Lee, Carol",3031000003,carol@berkeley.edu,4,???,Dropped
+`)
+	entries := importRoster(path)
+	if len(entries) != 2 {
+		t.Fatalf("Got %d roster entries; expected 2", len(entries))
+	}
+	if alice := entries[0]; alice.Student.SID != 3031000001 || alice.Student.GradingBasis != grades.BasisPassNoPass || alice.Status != statusEnrolled {
+		t.Errorf("Got roster entry %+v for %+v", alice, alice.Student)
+	}
+	if bob := entries[1]; bob.Student.GradingBasis != grades.BasisLetter || bob.Status != statusWaitlisted {
+		t.Errorf("Got roster entry %+v for %+v", bob, bob.Student)
+	}
+
+	expectPanic(t, "Unknown grading basis of an enrolled student", func() {
+		importRoster(writeTemp(t, "roster.csv", "Student ID,Grading Basis\n3031000001,???\n"))
+	})
+}
+
+func TestReconcileExactSID(t *testing.T) {
+	entries := []*rosterEntry{
+		{Student: &grades.Student{SID: 3031000001, Name: "Smith, Alice", Email: "alice@berkeley.edu"}},
+	}
+	resolver, err := newIdentityResolver(entries, []string{"sid", "sid-typo", "email"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A dropped student whose SID is one digit off from Alice's is listed
+	// before Alice.
+	ids := []identity{
+		{Source: "Gradescope", Key: "dan@berkeley.edu", SID: "3031000011", Email: "dan@berkeley.edu", Name: "Dan Dropped"},
+		{Source: "Gradescope", Key: "alice@berkeley.edu", SID: "3031000001", Email: "alice@berkeley.edu", Name: "Alice Smith"},
+	}
+	if matched := reconcile("Gradescope", entries, ids, resolver); len(matched) != 1 || matched[3031000001] != 1 {
+		t.Errorf("Got matches %v; expected Alice's row to match her SID", matched)
+	}
+	if match := resolver.matches[mappingFileKey("Gradescope", "dan@berkeley.edu")]; match.SID != 0 {
+		t.Errorf("Dropped student recorded as matching SID %d", match.SID)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	var output bytes.Buffer
+	warnings = &output
+	defer func() {
+		warnings = os.Stderr
+	}()
+
+	entries := importRoster(writeTemp(t, "roster.csv", `Name,Student ID,Email Address,Enrollment Status
+"Smith, Alice",3031000001,alice@berkeley.edu,Enrolled
+"Jones, Bob",3031000002,bob@berkeley.edu,Waitlisted
+"Lee, Carol",3031000003,carol@berkeley.edu,Dropped
+`))
+	resolver, err := newIdentityResolver(entries, []string{"sid", "email"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := []identity{
+		{Source: "Gradescope", Key: "alice@berkeley.edu", SID: "3031000001", Email: "alice@berkeley.edu", Name: "Alice Smith"},
+		{Source: "Gradescope", Key: "carol@berkeley.edu", SID: "3031000003", Email: "carol@berkeley.edu", Name: "Carol Lee"},
+		{Source: "Gradescope", Key: "eve@berkeley.edu", SID: "3031000005", Email: "eve@berkeley.edu", Name: "Eve Park"},
+	}
+	matched := reconcile("Gradescope", entries, ids, resolver)
+	if len(matched) != 1 || matched[3031000001] != 0 {
+		t.Errorf("Got matches %v; expected only Alice", matched)
+	}
+
+	// Dropped students are not on the roster, so their rows are reported
+	// with the students missing from it.
+	expected := `Reconciliation report for Gradescope:
+  In Gradescope but not on the roster:
+    Carol Lee (SID "3031000003", carol@berkeley.edu)
+    Eve Park (SID "3031000005", eve@berkeley.edu)
+  On the roster but not in Gradescope:
+    Jones, Bob (SID 3031000002, bob@berkeley.edu)
+`
+	if output.String() != expected {
+		t.Errorf("Got report %q; expected %q", output.String(), expected)
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cs161-staff/grades"
+)
+
+// Column names and special rows used in the Canvas gradebook export.
+const (
+	canvasStudent        = "Student"
+	canvasID             = "ID"
+	canvasSISUserID      = "SIS User ID"
+	canvasSISLoginID     = "SIS Login ID"
+	canvasSection        = "Section"
+	canvasPointsPossible = "Points Possible"
+	canvasManualPosting  = "Manual Posting"
+	canvasExcused        = "EX"
+)
+
+// canvasAssignmentColumn matches the assignment columns of the Canvas
+// gradebook, which are named with the assignment name followed by its Canvas
+// ID in parentheses.
+var canvasAssignmentColumn = regexp.MustCompile(`^(.*) \((\d+)\)$`)
+
+// canvasGradebookStudent is a student's row in the Canvas gradebook export.
+type canvasGradebookStudent struct {
+	// Name is the student's name on Canvas.
+	Name string
+
+	// CanvasID is the student's Canvas user ID.
+	CanvasID string
+
+	// SID is the student's SIS user ID, which is their student ID.
+	SID string
+
+	// Login is the student's SIS login ID.
+	Login string
+
+	// Section is the student's sections on Canvas.
+	Section string
+
+	// Submissions is the student's submissions, keyed by assignment name.
+	Submissions map[string]grades.AssignmentSubmission
+}
+
+// Identity returns the student's identity on Canvas, keyed by Canvas ID.
+func (student *canvasGradebookStudent) Identity() identity {
+	return identity{
+		Source: "Canvas",
+		Key:    student.CanvasID,
+		SID:    student.SID,
+		Name:   student.Name,
+	}
+}
+
+// importCanvas imports and returns the students described in the Canvas
+// gradebook export at the given path. Assignment columns are matched to the
+// given assignments by name. If category is not empty, columns that do not
+// match an assignment become new assignments in that category, using the
+// "Points Possible" row as the maximum score, which are returned without
+// adding them to the given assignments; otherwise they are reported to stderr
+// and skipped. Excused submissions are marked as dropped.
+func importCanvas(path string, assignments map[string]*grades.Assignment, categories map[string]*grades.Category, category string) ([]*canvasGradebookStudent, map[string]*grades.Assignment) {
+	if _, ok := categories[category]; category != "" && !ok {
+		panic(errors.New("Unknown Canvas category " + category))
+	}
+
+	reader, err := NewDictReaderFromPath(path)
+	panicIfErr(err)
+
+	// Map the assignment columns to assignment names.
+	columns := make(map[string]string)
+	for _, column := range reader.Header() {
+		match := canvasAssignmentColumn.FindStringSubmatch(column)
+		if match == nil {
+			continue
+		}
+		name := match[1]
+		if _, ok := assignments[name]; !ok && category == "" {
+			warn("Canvas column %s does not match any assignment", column)
+			continue
+		}
+		columns[column] = name
+	}
+
+	newAssignments := make(map[string]*grades.Assignment)
+	students := make([]*canvasGradebookStudent, 0)
+	for row, err := reader.Read(); err != io.EOF; row, err = reader.Read() {
+		panicIfErr(err)
+
+		switch strings.TrimSpace(row[canvasStudent]) {
+		case canvasManualPosting:
+			continue
+		case canvasPointsPossible:
+			for column, name := range columns {
+				pointsPossible, err := strconv.ParseFloat(strings.TrimSpace(row[column]), 64)
+				panicIfErr(err)
+				if assignment, ok := assignments[name]; ok {
+					if assignment.MaxScore != pointsPossible {
+						warn("Assignment %s has %f points possible on Canvas but %f possible", name, pointsPossible, assignment.MaxScore)
+					}
+					continue
+				}
+				newAssignments[name] = &grades.Assignment{
+					Name:         name,
+					CategoryName: category,
+					MaxScore:     pointsPossible,
+					Weight:       1.0,
+					SlipGroup:    -1,
+				}
+			}
+			continue
+		}
+
+		student := &canvasGradebookStudent{
+			Name:        strings.TrimSpace(row[canvasStudent]),
+			CanvasID:    strings.TrimSpace(row[canvasID]),
+			SID:         strings.TrimSpace(row[canvasSISUserID]),
+			Login:       strings.TrimSpace(row[canvasSISLoginID]),
+			Section:     strings.TrimSpace(row[canvasSection]),
+			Submissions: make(map[string]grades.AssignmentSubmission, len(columns)),
+		}
+		for column, name := range columns {
+			var submission grades.AssignmentSubmission
+			switch value := strings.TrimSpace(row[column]); value {
+			case "":
+				submission.Status = grades.StatusMissing
+			case canvasExcused:
+				submission.Dropped = true
+				submission.Comments = []string{"Excused on Canvas"}
+			default:
+				submission.Score, err = strconv.ParseFloat(value, 64)
+				if err != nil {
+					panic(fmt.Errorf("Invalid Canvas entry for %s on %s: %w", student.Name, name, err))
+				}
+			}
+			student.Submissions[name] = submission
+		}
+		students = append(students, student)
+	}
+
+	for _, name := range columns {
+		if _, ok := assignments[name]; !ok && newAssignments[name] == nil {
+			panic(fmt.Errorf("Canvas column for %s is not an assignment, and there is no %s row to add it with", name, canvasPointsPossible))
+		}
+	}
+	return students, newAssignments
+}
+
+// exportCanvas writes the students' total scores to a CSV at the given path
+// that can be imported into the Canvas gradebook as the given column. Only
+// students that were matched to the Canvas gradebook are written, since Canvas
+// requires their Canvas IDs. Totals are written out of 100 points, rounded to
+// the given number of decimal places.
+func exportCanvas(path string, column string, canvasStudents map[int]*canvasGradebookStudent, reports map[int]*grades.GradeReport, rounding int) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{canvasStudent, canvasID, canvasSISUserID, canvasSISLoginID, canvasSection, column})
+	writer.Write([]string{"    " + canvasPointsPossible, "", "", "", "", "100"})
+	for _, sid := range sortedSIDs(reports) {
+		student, ok := canvasStudents[sid]
+		if !ok {
+			continue
+		}
+		writer.Write([]string{
+			student.Name,
+			student.CanvasID,
+			student.SID,
+			student.Login,
+			student.Section,
+			grades.FormatPercent(reports[sid].TotalScore, rounding),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cs161-staff/grades"
+)
+
+func TestImportCanvas(t *testing.T) {
+	categories := map[string]*grades.Category{"Participation": {Name: "Participation"}}
+	assignments := map[string]*grades.Assignment{
+		"Quiz 1": {Name: "Quiz 1", CategoryName: "Participation", MaxScore: 5},
+	}
+	path := writeTemp(t, "canvas.csv", `Student,ID,SIS User ID,SIS Login ID,Section,Quiz 1 (101),Survey (102)
+    Points Possible,,,,,5,1
+"Smith, Alice",1,3031000001,alice,LEC 001,4,EX
+`)
+	students, newAssignments := importCanvas(path, assignments, categories, "Participation")
+	if len(students) != 1 {
+		t.Fatalf("Got %d students; expected 1", len(students))
+	}
+	if len(assignments) != 1 {
+		t.Errorf("The given assignments were changed: %v", assignments)
+	}
+	if survey := newAssignments["Survey"]; survey == nil || survey.MaxScore != 1 || survey.CategoryName != "Participation" || len(newAssignments) != 1 {
+		t.Errorf("Got new assignments %v", newAssignments)
+	}
+	if submission := students[0].Submissions["Quiz 1"]; submission.Score != 4 {
+		t.Errorf("Got Quiz 1 submission %+v", submission)
+	}
+	if submission := students[0].Submissions["Survey"]; !submission.Dropped {
+		t.Errorf("Got Survey submission %+v; expected it to be excused", submission)
+	}
+
+	// New columns cannot be added without their points possible.
+	path = writeTemp(t, "canvas.csv", `Student,ID,SIS User ID,SIS Login ID,Section,Survey (102)
+"Smith, Alice",1,3031000001,alice,LEC 001,1
+`)
+	defer func() {
+		if recover() == nil {
+			t.Error("Canvas column without points possible accepted")
+		}
+	}()
+	importCanvas(path, assignments, categories, "Participation")
+}
+
+func TestMergeSubmissions(t *testing.T) {
+	all := make(map[int]map[string]grades.AssignmentSubmission)
+	mergeSubmissions(all, 1, "Gradescope", map[string]grades.AssignmentSubmission{
+		"HW 1": {Score: 5},
+		"HW 2": {Status: grades.StatusMissing},
+	})
+	mergeSubmissions(all, 1, "Canvas", map[string]grades.AssignmentSubmission{
+		"HW 1": {Status: grades.StatusMissing},
+		"HW 2": {Score: 3},
+	})
+	if all[1]["HW 1"].Score != 5 || all[1]["HW 2"].Score != 3 {
+		t.Errorf("Got merged submissions %v", all[1])
+	}
+}
+
+func TestExportCanvas(t *testing.T) {
+	canvasStudents := map[int]*canvasGradebookStudent{
+		3031000002: {Name: "Jones, Bob", CanvasID: "2", SID: "3031000002", Login: "bob", Section: "LEC 001"},
+	}
+	reports := map[int]*grades.GradeReport{
+		3031000001: testReport(3031000001, "Smith, Alice", 0.9, "A-", "A-"),
+		3031000002: testReport(3031000002, "Jones, Bob", 0.8766, "B+", "B+"),
+	}
+	path := filepath.Join(t.TempDir(), "canvas.csv")
+	if err := exportCanvas(path, "Final Grade", canvasStudents, reports, 1); err != nil {
+		t.Fatal(err)
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only students matched to Canvas are written, with totals out of 100.
+	expected := `Student,ID,SIS User ID,SIS Login ID,Section,Final Grade
+"    Points Possible",,,,,100
+"Jones, Bob",2,3031000002,bob,LEC 001,87.7
+`
+	if string(contents) != expected {
+		t.Errorf("Got Canvas CSV %q; expected %q", contents, expected)
+	}
+}
@@ -47,3 +47,7 @@ func (reader *DictReader) Read() (map[string]string, error) {
 	}
 	return ret, nil
 }
+
+func (reader *DictReader) Header() []string {
+	return reader.header
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// emailMain renders each student's grade report as an email and writes the
+// messages to an mbox file or a directory of .eml files. The emails are not
+// sent.
+func emailMain(args []string) {
+	flags := flag.NewFlagSet("fromgradescope email", flag.ExitOnError)
+	in := &inputs{}
+	in.register(flags)
+
+	var templatePath string
+	var subject string
+	var from string
+	var mboxPath string
+	var emlDir string
+	var rounding int
+	flags.StringVar(&templatePath, "template", "", "text/template file for the email body, executed with each student's report")
+	flags.StringVar(&subject, "subject", "Grade report for {{.Name}}", "text/template for the email subject")
+	flags.StringVar(&from, "from", "", "Sender address of the emails")
+	flags.StringVar(&mboxPath, "mbox", "", "Output mbox file")
+	flags.StringVar(&emlDir, "eml", "", "Output directory for .eml files, named by SID")
+	flags.IntVar(&rounding, "round", 0, "Number of decimal places to round percentages to")
+
+	flags.Parse(args)
+
+	if !in.valid() || templatePath == "" || from == "" || (mboxPath == "") == (emlDir == "") {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	bodyTemplate, err := template.ParseFiles(templatePath)
+	panicIfErr(err)
+	subjectTemplate, err := template.New("subject").Parse(subject)
+	panicIfErr(err)
+	sender, err := mail.ParseAddress(from)
+	panicIfErr(err)
+
+	result := compute(in)
+	categoryNames, assignmentNames := exportColumns(result.course.Categories, result.course.Assignments)
+
+	var mbox *bufio.Writer
+	if mboxPath != "" {
+		file, err := os.Create(mboxPath)
+		panicIfErr(err)
+		defer file.Close()
+		mbox = bufio.NewWriter(file)
+		defer mbox.Flush()
+	} else {
+		panicIfErr(os.MkdirAll(emlDir, 0755))
+	}
+
+	date := time.Now()
+	for _, sid := range sortedSIDs(result.reports) {
+		view := newReportView(result.reports[sid], categoryNames, assignmentNames, rounding)
+		if view.Email == "" {
+			warn("Skipping email to %s (SID %d) with no email address", view.Name, sid)
+			continue
+		}
+		message, err := renderEmail(sender, view, subjectTemplate, bodyTemplate, date)
+		panicIfErr(err)
+		if mbox != nil {
+			panicIfErr(writeMbox(mbox, sender.Address, date, message))
+		} else {
+			panicIfErr(os.WriteFile(filepath.Join(emlDir, fmt.Sprintf("%d.eml", sid)), message, 0644))
+		}
+	}
+}
+
+// renderEmail renders an RFC 5322 message to the student described by the view,
+// with the subject and body rendered from the templates. Lines end in CRLF.
+func renderEmail(sender *mail.Address, view *reportView, subjectTemplate *template.Template, bodyTemplate *template.Template, date time.Time) ([]byte, error) {
+	var subject strings.Builder
+	if err := subjectTemplate.Execute(&subject, view); err != nil {
+		return nil, err
+	}
+	if strings.ContainsAny(subject.String(), "\r\n") {
+		return nil, errors.New("Email subject contains a line break")
+	}
+	var body bytes.Buffer
+	if err := bodyTemplate.Execute(&body, view); err != nil {
+		return nil, err
+	}
+
+	domain := "localhost"
+	if at := strings.LastIndex(sender.Address, "@"); at >= 0 {
+		domain = sender.Address[at+1:]
+	}
+	recipient := &mail.Address{Name: view.Name, Address: view.Email}
+
+	var message bytes.Buffer
+	headers := [][2]string{
+		{"From", sender.String()},
+		{"To", recipient.String()},
+		{"Subject", mime.QEncoding.Encode("utf-8", subject.String())},
+		{"Date", date.Format(time.RFC1123Z)},
+		{"Message-ID", fmt.Sprintf("<grades.%d.%d@%s>", view.SID, date.Unix(), domain)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "text/plain; charset=utf-8"},
+		{"Content-Transfer-Encoding", "quoted-printable"},
+	}
+	for _, header := range headers {
+		fmt.Fprintf(&message, "%s: %s\r\n", header[0], header[1])
+	}
+	message.WriteString("\r\n")
+	encoder := quotedprintable.NewWriter(&message)
+	normalized := strings.ReplaceAll(body.String(), "\r\n", "\n")
+	if _, err := encoder.Write([]byte(strings.ReplaceAll(normalized, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return message.Bytes(), nil
+}
+
+// writeMbox appends a message to an mbox file in the mboxrd format, quoting
+// any body lines that would be mistaken for the start of a message.
+func writeMbox(mbox *bufio.Writer, sender string, date time.Time, message []byte) error {
+	fmt.Fprintf(mbox, "From %s %s\n", sender, date.UTC().Format(time.ANSIC))
+	lines := strings.Split(strings.ReplaceAll(string(message), "\r\n", "\n"), "\n")
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimLeft(line, ">"), "From ") {
+			line = ">" + line
+		}
+		mbox.WriteString(line)
+		mbox.WriteString("\n")
+	}
+	_, err := mbox.WriteString("\n")
+	return err
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"net/mail"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func TestRenderEmail(t *testing.T) {
+	sender := &mail.Address{Name: "Course Staff", Address: "staff@example.edu"}
+	view := &reportView{Name: "Alice Smith", SID: 3031000001, Email: "alice@example.edu", Total: "95.00%"}
+	subject := template.Must(template.New("subject").Parse("Grades for {{.Name}}"))
+	body := template.Must(template.New("body").Parse("Total: {{.Total}}\nFrom the staff\n"))
+	date := time.Date(2021, 12, 20, 12, 0, 0, 0, time.UTC)
+
+	message, err := renderEmail(sender, view, subject, body, date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := mail.ReadMessage(bytes.NewReader(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for header, expected := range map[string]string{
+		"From":       `"Course Staff" <staff@example.edu>`,
+		"To":         `"Alice Smith" <alice@example.edu>`,
+		"Subject":    "Grades for Alice Smith",
+		"Message-Id": "<grades.3031000001.1640001600@example.edu>",
+	} {
+		if got := parsed.Header.Get(header); got != expected {
+			t.Errorf("Got %s header %q; expected %q", header, got, expected)
+		}
+	}
+	if !bytes.Contains(message, []byte("Total: 95.00%\r\nFrom the staff\r\n")) {
+		t.Errorf("Body does not have CRLF line endings: %q", message)
+	}
+
+	newline := template.Must(template.New("subject").Parse("Grades\n"))
+	if _, err := renderEmail(sender, view, newline, body, date); err == nil {
+		t.Error("Subject with a line break accepted")
+	}
+}
+
+func TestWriteMbox(t *testing.T) {
+	var output bytes.Buffer
+	mbox := bufio.NewWriter(&output)
+	date := time.Date(2021, 12, 20, 12, 0, 0, 0, time.UTC)
+	message := []byte("Subject: Grades\r\n\r\nFrom the staff\r\n>From before\r\nDone\r\n")
+	if err := writeMbox(mbox, "staff@example.edu", date, message); err != nil {
+		t.Fatal(err)
+	}
+	mbox.Flush()
+
+	expected := strings.Join([]string{
+		"From staff@example.edu Mon Dec 20 12:00:00 2021",
+		"Subject: Grades",
+		"",
+		">From the staff",
+		">>From before",
+		"Done",
+		"",
+		"",
+	}, "\n") + "\n"
+	if output.String() != expected {
+		t.Errorf("Got mbox %q; expected %q", output.String(), expected)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"io"
+	"sort"
+
+	"github.com/cs161-staff/grades"
+)
+
+// exportColumns returns the names of the categories and assignments exported
+// for each student, in column order. Categories are sorted by name, and
+// assignments are sorted by category and then by name.
+func exportColumns(categories map[string]*grades.Category, assignments map[string]*grades.Assignment) ([]string, []string) {
+	categoryNames := make([]string, 0, len(categories))
+	for name := range categories {
+		categoryNames = append(categoryNames, name)
+	}
+	sort.Strings(categoryNames)
+
+	assignmentNames := make([]string, 0, len(assignments))
+	for name := range assignments {
+		assignmentNames = append(assignmentNames, name)
+	}
+	sort.Slice(assignmentNames, func(i, j int) bool {
+		a := assignments[assignmentNames[i]]
+		b := assignments[assignmentNames[j]]
+		if a.CategoryName != b.CategoryName {
+			return a.CategoryName < b.CategoryName
+		}
+		return a.Name < b.Name
+	})
+
+	return categoryNames, assignmentNames
+}
+
+// exportGrades writes the finalized grade reports as a CSV with one row per
+// student, sorted by SID, and a column for each score in the order of
+// exportColumns. Scores are written as percentages rounded to the given number
+// of decimal places.
+func exportGrades(writer io.Writer, reports map[int]*grades.GradeReport, categories map[string]*grades.Category, assignments map[string]*grades.Assignment, rounding int) error {
+	categoryNames, assignmentNames := exportColumns(categories, assignments)
+	return grades.WriteReportsCSV(writer, reports, categoryNames, assignmentNames, rounding)
+}
+
+// sortedSIDs returns the SIDs of the reports in sorted order.
+func sortedSIDs(reports map[int]*grades.GradeReport) []int {
+	sids := make([]int, 0, len(reports))
+	for sid := range reports {
+		sids = append(sids, sid)
+	}
+	sort.Ints(sids)
+	return sids
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+
+	"github.com/cs161-staff/grades"
+)
+
+// testCourse returns the categories and assignments of a small course with two
+// homework assignments and a final exam.
+func testCourse() (map[string]*grades.Category, map[string]*grades.Assignment) {
+	categories := map[string]*grades.Category{
+		"Homework": {Name: "Homework", Weight: 0.5},
+		"Exams":    {Name: "Exams", Weight: 0.5},
+	}
+	assignments := map[string]*grades.Assignment{
+		"HW 2":  {Name: "HW 2", CategoryName: "Homework", MaxScore: 10, Weight: 1},
+		"HW 1":  {Name: "HW 1", CategoryName: "Homework", MaxScore: 10, Weight: 1},
+		"Final": {Name: "Final", CategoryName: "Exams", MaxScore: 100, Weight: 1},
+	}
+	return categories, assignments
+}
+
+// testReport returns a grade report in the course from testCourse with the
+// given total score, letter and final grade.
+func testReport(sid int, name string, total float64, letter string, grade string) *grades.GradeReport {
+	categories, assignments := testCourse()
+	report := &grades.GradeReport{
+		Student:     grades.NewStudent(sid, name, categories, assignments, nil),
+		TotalScore:  total,
+		Letter:      letter,
+		Grade:       grade,
+		Categories:  make(map[string]*grades.ReportCategory),
+		Assignments: make(map[string]*grades.ReportAssignment),
+	}
+	for name := range categories {
+		report.Categories[name] = &grades.ReportCategory{Raw: total, Adjusted: total, Weighted: total / 2}
+	}
+	for name := range assignments {
+		report.Assignments[name] = &grades.ReportAssignment{Raw: total, Adjusted: total, Weighted: total / 2}
+	}
+	return report
+}
+
+func TestExportGrades(t *testing.T) {
+	categories, assignments := testCourse()
+	second := testReport(2, "Bob", 0.8, "B-", "P")
+	first := testReport(1, "Alice", 0.91236, "A-", "A-")
+	first.Assignments["HW 1"].Comments = []string{"Late multiplier x0.9"}
+	first.Categories["Homework"].Comments = []string{"Dropped HW 2"}
+	first.Comments = []string{"Capped at B"}
+	reports := map[int]*grades.GradeReport{2: second, 1: first}
+
+	var output bytes.Buffer
+	if err := exportGrades(&output, reports, categories, assignments, 2); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&output).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("Got %d rows; expected a header and 2 students", len(rows))
+	}
+
+	// Assignments are sorted by category and then by name, followed by the
+	// categories sorted by name.
+	expected := []string{
+		"SID", "Name",
+		"Final Raw", "Final Adjusted", "Final Weighted",
+		"HW 1 Raw", "HW 1 Adjusted", "HW 1 Weighted",
+		"HW 2 Raw", "HW 2 Adjusted", "HW 2 Weighted",
+		"Category: Exams Raw", "Category: Exams Adjusted", "Category: Exams Weighted",
+		"Category: Homework Raw", "Category: Homework Adjusted", "Category: Homework Weighted",
+		"Total", "Letter", "Grade", "Comments",
+	}
+	if !reflect.DeepEqual(rows[0], expected) {
+		t.Errorf("Got header %v; expected %v", rows[0], expected)
+	}
+
+	// Students are sorted by SID and scores are rounded percentages.
+	expected = []string{
+		"1", "Alice",
+		"91.24", "91.24", "45.62",
+		"91.24", "91.24", "45.62",
+		"91.24", "91.24", "45.62",
+		"91.24", "91.24", "45.62",
+		"91.24", "91.24", "45.62",
+		"91.24", "A-", "A-", "HW 1: Late multiplier x0.9; Homework: Dropped HW 2; Capped at B",
+	}
+	if !reflect.DeepEqual(rows[1], expected) {
+		t.Errorf("Got row %v; expected %v", rows[1], expected)
+	}
+	if sid, comments := rows[2][0], rows[2][len(rows[2])-1]; sid != "2" || comments != "" {
+		t.Errorf("Got SID %s with comments %q; expected SID 2 without comments", sid, comments)
+	}
+}
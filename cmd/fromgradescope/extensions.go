@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/cs161-staff/grades"
+	"github.com/cs161-staff/grades/policies/extensions"
+)
+
+// importExtensions imports the CSV of extensions at the given path, with SID,
+// Assignment and Extension columns and optional Email and Name columns, and
+// returns each student's extensions by SID and assignment name. An extension
+// is written in any format that extensions.ParseExtension accepts, such as
+// "2", "36h", "50%" or "2021-10-01T17:00:00-07:00". Rows are matched to
+// students with the resolver.
+func importExtensions(path string, assignments map[string]*grades.Assignment, resolver *identityResolver) map[int]map[string]extensions.Extension {
+	reader, err := NewDictReaderFromPath(path)
+	panicIfErr(err)
+
+	imported := make(map[int]map[string]extensions.Extension)
+	for row, err := reader.Read(); err != io.EOF; row, err = reader.Read() {
+		panicIfErr(err)
+
+		name := strings.TrimSpace(row["Assignment"])
+		if _, ok := assignments[name]; !ok {
+			panic(fmt.Errorf("Unknown assignment in extensions: %s", name))
+		}
+		extension, err := extensions.ParseExtension(row["Extension"])
+		panicIfErr(err)
+		sid := resolver.ResolveRow("Extensions", row)
+		if sid == 0 {
+			continue
+		}
+		if _, ok := imported[sid]; !ok {
+			imported[sid] = make(map[string]extensions.Extension)
+		}
+		if _, ok := imported[sid][name]; ok {
+			panic(fmt.Errorf("Duplicate extension for SID %d on assignment %s", sid, name))
+		}
+		imported[sid][name] = extension
+	}
+	return imported
+}
+
+// applyExtensions inserts a stage into the course's pipeline that applies the
+// extensions with the extensions policy. Students who are not on the roster
+// are reported and skipped.
+func applyExtensions(course *grades.CourseConfig, roster grades.Roster, imported map[int]map[string]extensions.Extension) {
+	sids := make([]int, 0, len(imported))
+	for sid := range imported {
+		sids = append(sids, sid)
+	}
+	sort.Ints(sids)
+	params := make(map[int]map[string]extensions.Extension, len(imported))
+	for _, sid := range sids {
+		if _, ok := roster[sid]; !ok {
+			warn("SID %d in extensions is not on the roster", sid)
+			continue
+		}
+		params[sid] = imported[sid]
+	}
+	if len(params) == 0 {
+		return
+	}
+	encoded, err := json.Marshal(params)
+	panicIfErr(err)
+	course.Pipeline, err = course.Pipeline.Insert(grades.Stage{Name: "extensions", Params: encoded})
+	panicIfErr(err)
+}
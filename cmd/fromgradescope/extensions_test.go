@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cs161-staff/grades"
+	"github.com/cs161-staff/grades/policies/extensions"
+)
+
+func TestImportExtensions(t *testing.T) {
+	assignments := map[string]*grades.Assignment{
+		"HW 1": {Name: "HW 1", CategoryName: "Homework", MaxScore: 10},
+		"HW 2": {Name: "HW 2", CategoryName: "Homework", MaxScore: 10},
+	}
+	entries := []*rosterEntry{
+		{Student: &grades.Student{SID: 3031000001, Name: "Smith, Alice", Email: "alice@berkeley.edu"}},
+	}
+	resolver, err := newIdentityResolver(entries, []string{"sid", "email"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := writeTemp(t, "extensions.csv", `SID,Email,Assignment,Extension
+3031000001,,HW 1,2
+,alice@berkeley.edu,HW 2,2021-10-01 17:00:00 -0700
+3031000099,,HW 1,1
+`)
+	imported := importExtensions(path, assignments, resolver)
+	if len(imported) != 1 || len(imported[3031000001]) != 2 {
+		t.Fatalf("Got extensions %v", imported)
+	}
+	if extension := imported[3031000001]["HW 1"]; extension.Length != 48*time.Hour {
+		t.Errorf("Got extension %v on HW 1; expected 2 days", extension)
+	}
+	if extension := imported[3031000001]["HW 2"]; extension.Deadline.IsZero() {
+		t.Errorf("Got extension %v on HW 2; expected a new deadline", extension)
+	}
+
+	for _, rows := range []string{
+		"3031000001,,HW 3,1\n",
+		"3031000001,,HW 1,-1\n",
+		"3031000001,,HW 1,two days\n",
+		"3031000001,,HW 1,1\n3031000001,,HW 1,2\n",
+	} {
+		expectPanic(t, "Extensions "+rows, func() {
+			importExtensions(writeTemp(t, "extensions.csv", "SID,Email,Assignment,Extension\n"+rows), assignments, resolver)
+		})
+	}
+}
+
+func TestApplyExtensions(t *testing.T) {
+	course := &grades.CourseConfig{Pipeline: grades.Pipeline{{Name: "slipdays"}}}
+	roster := grades.Roster{1: {{SID: 1}}}
+	applyExtensions(course, roster, map[int]map[string]extensions.Extension{
+		1: {"HW 1": {Length: 36 * time.Hour}},
+		2: {"HW 1": {Length: 24 * time.Hour}},
+	})
+	if len(course.Pipeline) != 2 || course.Pipeline[0].Name != "extensions" || string(course.Pipeline[0].Params) != `{"1":{"HW 1":"36h0m0s"}}` {
+		t.Errorf("Got pipeline %+v", course.Pipeline)
+	}
+
+	course = &grades.CourseConfig{Pipeline: grades.Pipeline{{Name: "slipdays"}}}
+	applyExtensions(course, roster, map[int]map[string]extensions.Extension{2: {"HW 1": {Length: 24 * time.Hour}}})
+	if len(course.Pipeline) != 1 {
+		t.Errorf("Extensions stage added with no students on the roster: %+v", course.Pipeline)
+	}
+}
@@ -8,8 +8,12 @@ import (
 	"io"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cs161-staff/grades"
+	_ "github.com/cs161-staff/grades/policies/all"
+	"github.com/cs161-staff/grades/policies/latemultipliers"
 )
 
 func panicIfErr(err error) {
@@ -36,7 +40,7 @@ func importCategories(path string) map[string]*grades.Category {
 		drops64, err := strconv.ParseInt(row["Drops"], 10, 64)
 		panicIfErr(err)
 		drops := int(drops64)
-		slipDays64, err := strconv.ParseInt(row["Drops"], 10, 32)
+		slipDays64, err := strconv.ParseInt(row["Slip Days"], 10, 32)
 		panicIfErr(err)
 		slipDays := int(slipDays64)
 		if _, ok := categories[name]; ok {
@@ -54,8 +58,17 @@ func importCategories(path string) map[string]*grades.Category {
 	return categories
 }
 
+// parseTime parses a time with grades.ParseTime, returning the zero time if the
+// value is empty.
+func parseTime(value string) (time.Time, error) {
+	if strings.TrimSpace(value) == "" {
+		return time.Time{}, nil
+	}
+	return grades.ParseTime(value)
+}
+
 // importAssignments imports and returns the assignments described in the CSV
-// at the given path.
+// at the given path. The Released and Due columns are optional.
 func importAssignments(path string, categories map[string]*grades.Category) map[string]*grades.Assignment {
 	reader, err := NewDictReaderFromPath(path)
 	panicIfErr(err)
@@ -72,59 +85,238 @@ func importAssignments(path string, categories map[string]*grades.Category) map[
 		slipGroup64, err := strconv.ParseInt(row["Slip Group"], 10, 64)
 		panicIfErr(err)
 		slipGroup := int(slipGroup64)
+		released, err := parseTime(row["Released"])
+		panicIfErr(err)
+		due, err := parseTime(row["Due"])
+		panicIfErr(err)
 		if _, ok := assignments[name]; ok {
 			panic(errors.New(fmt.Sprintf("Duplicate assignment specified in imported CSV: %s", name)))
 		}
 		if _, ok := categories[category]; !ok {
 			panic(errors.New(fmt.Sprintf("Assignment %s references unknown category %s", name, category)))
 		}
+		if !released.IsZero() && !due.IsZero() && !due.After(released) {
+			panic(fmt.Errorf("Assignment %s is due before it is released", name))
+		}
 		assignments[name] = &grades.Assignment{
 			Name:         name,
 			CategoryName: category,
 			MaxScore:     maxScore,
 			Weight:       weight,
 			SlipGroup:    slipGroup,
+			Released:     released,
+			Due:          due,
 		}
 	}
 
 	return assignments
 }
 
+// importBins imports and returns the grade bins described in the CSV at the
+// given path.
+func importBins(path string) grades.GradeBins {
+	reader, err := NewDictReaderFromPath(path)
+	panicIfErr(err)
+
+	bins := make(grades.GradeBins, 0)
+	for row, err := reader.Read(); err != io.EOF; row, err = reader.Read() {
+		panicIfErr(err)
+		min, err := strconv.ParseFloat(row["Min"], 64)
+		panicIfErr(err)
+		bins = append(bins, grades.GradeBin{
+			Letter: row["Letter"],
+			Min:    min,
+		})
+	}
+
+	return bins
+}
+
+// parseScale parses a comma-separated list of late multipliers.
+func parseScale(value string) []float64 {
+	scale := make([]float64, 0)
+	if value == "" {
+		return scale
+	}
+	for _, factor := range strings.Split(value, ",") {
+		multiplier, err := strconv.ParseFloat(strings.TrimSpace(factor), 64)
+		panicIfErr(err)
+		scale = append(scale, multiplier)
+	}
+	return scale
+}
+
+// importCourse imports the course configuration from the CSV inputs, with the
+// default pipeline of slip days, late multipliers and drops.
+func importCourse(categoriesPath string, assignmentsPath string, binsPath string, lateScale string, lateGrace time.Duration) *grades.CourseConfig {
+	course := &grades.CourseConfig{
+		Categories:         importCategories(categoriesPath),
+		GradeBins:          grades.DefaultGradeBins,
+		PassingLetter:      grades.DefaultPassingLetter,
+		SatisfactoryLetter: grades.DefaultSatisfactoryLetter,
+	}
+	course.Assignments = importAssignments(assignmentsPath, course.Categories)
+	if binsPath != "" {
+		course.GradeBins = importBins(binsPath)
+	}
+	lateParams, err := json.Marshal(latemultipliers.Params{
+		Scale: parseScale(lateScale),
+		Grace: lateGrace.String(),
+	})
+	panicIfErr(err)
+	course.Pipeline = grades.Pipeline{
+		{Name: "slipdays"},
+		{Name: "latemultipliers", Params: lateParams},
+		{Name: "drops"},
+	}
+	return course
+}
+
+// buildRoster builds a roster with one outcome for each student in the roster
+// entries, using their submissions from the data sources.
+func buildRoster(entries []*rosterEntry, submissions map[int]map[string]grades.AssignmentSubmission, categories map[string]*grades.Category, assignments map[string]*grades.Assignment) grades.Roster {
+	roster := make(grades.Roster, len(entries))
+	for _, entry := range entries {
+		student := grades.NewStudent(entry.Student.SID, entry.Student.Name, categories, assignments, submissions[entry.Student.SID])
+		student.Email = entry.Student.Email
+		student.Units = entry.Student.Units
+		student.GradingBasis = entry.Student.GradingBasis
+		roster[student.SID] = []*grades.Student{student}
+	}
+	return roster
+}
+
+// mergeSubmissions adds the submissions from the named source to the
+// submissions for the given SID. A missing submission never replaces an
+// existing one, and replacing an existing submission is reported.
+func mergeSubmissions(all map[int]map[string]grades.AssignmentSubmission, sid int, source string, submissions map[string]grades.AssignmentSubmission) {
+	merged, ok := all[sid]
+	if !ok {
+		merged = make(map[string]grades.AssignmentSubmission, len(submissions))
+		all[sid] = merged
+	}
+	for name, submission := range submissions {
+		if existing, ok := merged[name]; ok && existing.Status != grades.StatusMissing {
+			if submission.Status == grades.StatusMissing {
+				continue
+			}
+			warn("%s score for SID %d on %s replaces an earlier score", source, sid, name)
+		}
+		merged[name] = submission
+	}
+}
+
 func main() {
-	// Mandatory args.
-	var rosterPath string
-	var gradesPath string
-	var categoriesPath string
-	var assignmentsPath string
-	flag.StringVar(&rosterPath, "roster", "", "CSV roster downloaded from CalCentral")
-	flag.StringVar(&gradesPath, "grades", "", "CSV grades downloaded from Gradescope")
-	flag.StringVar(&categoriesPath, "categories", "", "CSV with assignment categories")
-	flag.StringVar(&assignmentsPath, "assignments", "", "CSV with assignments")
-
-	// Optional args.
-	var overridesPath string
-	var clobbersPath string
-	var extensionsPath string
-	var accommodationsPath string
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "email":
+			emailMain(os.Args[2:])
+			return
+		case "lookup":
+			lookupMain(os.Args[2:])
+			return
+		}
+	}
+	computeMain(os.Args[1:])
+}
+
+// computeMain computes grades from the inputs and writes the requested
+// exports.
+func computeMain(args []string) {
+	flags := flag.NewFlagSet("fromgradescope", flag.ExitOnError)
+	in := &inputs{}
+	in.register(flags)
+
 	var rounding int
 	var outputPath string
-	flag.StringVar(&overridesPath, "overrides", "", "CSV with score overrides")
-	flag.StringVar(&clobbersPath, "clobbers", "", "CSV with clobbers")
-	flag.StringVar(&extensionsPath, "extensions", "", "CSV with extensions")
-	flag.StringVar(&accommodationsPath, "accommodations", "", "CSV with accommodations for drops and slip days")
-	flag.IntVar(&rounding, "round", 0, "Number of decimal places to round to")
-	flag.StringVar(&outputPath, "output", "", "Output CSV file")
+	var registrarPath string
+	var canvasOutputPath string
+	var canvasColumn string
+	var htmlDir string
+	var jsonPath string
+	var mergePath string
+	var watchInputs bool
+	var watchInterval time.Duration
+	flags.IntVar(&rounding, "round", 0, "Number of decimal places to round percentages to")
+	flags.StringVar(&outputPath, "output", "", "Output CSV file (default stdout)")
+	flags.StringVar(&registrarPath, "registrar", "", "Output CSV file for the CalCentral grade upload")
+	flags.StringVar(&canvasOutputPath, "canvas-output", "", "Output CSV file of totals to import into Canvas (requires -canvas)")
+	flags.StringVar(&canvasColumn, "canvas-column", "Course Total (Computed)", "Canvas gradebook column for the exported totals")
+	flags.StringVar(&htmlDir, "html", "", "Output directory for per-student HTML grade reports, named by SID")
+	flags.StringVar(&jsonPath, "json", "", "Output JSON file of the finalized grade reports, for comparison with gradediff")
+	flags.StringVar(&mergePath, "merge", "", "JSON grade reports from a previous term; only students with an Incomplete grade in them are updated")
+	flags.BoolVar(&watchInputs, "watch", false, "Recompute whenever an input file changes, printing which students' totals or letter grades changed (the grades CSV is only written with -output)")
+	flags.DurationVar(&watchInterval, "watch-interval", time.Second, "How often to check the input files for changes with -watch")
 
-	flag.Parse()
+	flags.Parse(args)
 
-	if rosterPath == "" || gradesPath == "" || categoriesPath == "" || assignmentsPath == "" {
-		flag.Usage()
+	if !in.valid() {
+		flags.Usage()
 		os.Exit(1)
 	}
+	if canvasOutputPath != "" && in.canvasPath == "" {
+		panic(errors.New("-canvas-output requires a Canvas gradebook from -canvas"))
+	}
+	if mergePath != "" && htmlDir != "" {
+		panic(errors.New("-merge cannot be used with -html, since previous grade reports do not include each student's inputs"))
+	}
+
+	// In watch mode the cache is kept in memory between runs, so that only
+	// students whose inputs changed are recomputed.
+	cache := in.loadCache()
+	if cache == nil && watchInputs {
+		cache = grades.NewCache()
+	}
+
+	run := func() *computation {
+		result := computeCached(in, cache)
+		if mergePath != "" {
+			file, err := os.Open(mergePath)
+			panicIfErr(err)
+			previous, err := grades.ReadReportsJSON(file)
+			file.Close()
+			panicIfErr(err)
+			var replaced []int
+			result.reports, replaced = grades.MergeIncompletes(previous, result.reports)
+			fmt.Fprintf(os.Stderr, "Updated %d Incomplete grades in %d previous grade reports\n", len(replaced), len(previous))
+		}
+		categories := result.course.Categories
+		assignments := result.course.Assignments
+
+		if registrarPath != "" {
+			panicIfErr(exportRegistrar(registrarPath, result.rosterEntries, result.reports))
+		}
+
+		if canvasOutputPath != "" {
+			panicIfErr(exportCanvas(canvasOutputPath, canvasColumn, result.canvasStudents, result.reports, rounding))
+		}
 
-	categories := importCategories(categoriesPath)
-	assignments := importAssignments(assignmentsPath, categories)
+		if htmlDir != "" {
+			panicIfErr(exportHTML(htmlDir, result.reports, categories, assignments, rounding))
+		}
+
+		if jsonPath != "" {
+			file, err := os.Create(jsonPath)
+			panicIfErr(err)
+			err = grades.WriteReportsJSON(file, result.reports)
+			file.Close()
+			panicIfErr(err)
+		}
 
-	out, _ := json.MarshalIndent(assignments, "", "  ")
-	fmt.Println(string(out))
+		if outputPath != "" {
+			output, err := os.Create(outputPath)
+			panicIfErr(err)
+			defer output.Close()
+			panicIfErr(exportGrades(output, result.reports, categories, assignments, rounding))
+		} else if !watchInputs {
+			panicIfErr(exportGrades(os.Stdout, result.reports, categories, assignments, rounding))
+		}
+		return result
+	}
+
+	if watchInputs {
+		watch(in, watchInterval, run)
+	} else {
+		run()
+	}
 }
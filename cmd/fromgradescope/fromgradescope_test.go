@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTemp writes the contents to a file in a temporary directory and returns
+// its path.
+func writeTemp(t *testing.T, name string, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// expectPanic reports an error naming what was accepted if f does not panic.
+func expectPanic(t *testing.T, accepted string, f func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%s accepted", accepted)
+		}
+	}()
+	f()
+}
+
+func TestImportCategories(t *testing.T) {
+	path := writeTemp(t, "categories.csv", "Name,Weight,Has Late Multiplier,Drops,Slip Days\nHomework,0.5,true,1,3\n")
+	category := importCategories(path)["Homework"]
+	if category == nil || category.Drops != 1 || category.SlipDays != 3 || !category.HasLateMultiplier {
+		t.Errorf("Got category %+v", category)
+	}
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cs161-staff/grades"
+)
+
+// Column names and suffixes used in the Gradescope grades export. Each
+// assignment has a score column named after the assignment, followed by
+// columns named with the assignment name and one of the suffixes.
+const (
+	gradescopeName           = "Name"
+	gradescopeFirstName      = "First Name"
+	gradescopeLastName       = "Last Name"
+	gradescopeSID            = "SID"
+	gradescopeEmail          = "Email"
+	gradescopeSections       = "Sections"
+	gradescopeTotalLateness  = "Total Lateness (H:M:S)"
+	gradescopeMaxPoints      = " - Max Points"
+	gradescopeSubmissionTime = " - Submission Time"
+	gradescopeLateness       = " - Lateness (H:M:S)"
+	gradescopeStatus         = " - Status"
+)
+
+// gradescopeMetadata is the set of columns in the Gradescope export that
+// describe the student rather than an assignment.
+var gradescopeMetadata = map[string]bool{
+	gradescopeName:          true,
+	gradescopeFirstName:     true,
+	gradescopeLastName:      true,
+	gradescopeSID:           true,
+	gradescopeEmail:         true,
+	gradescopeSections:      true,
+	gradescopeTotalLateness: true,
+}
+
+// gradescopeSuffixes is the suffixes of the per-assignment columns other than
+// the score column.
+var gradescopeSuffixes = []string{
+	gradescopeMaxPoints,
+	gradescopeSubmissionTime,
+	gradescopeLateness,
+	gradescopeStatus,
+}
+
+// gradescopeStudent is a student's row in the Gradescope grades export.
+type gradescopeStudent struct {
+	// SID is the student ID as entered on Gradescope. It is kept as a string
+	// since it is entered by students and is not necessarily valid.
+	SID string
+
+	// Name is the student's name on Gradescope.
+	Name string
+
+	// Email is the student's email on Gradescope.
+	Email string
+
+	// Submissions is the student's submissions, keyed by assignment name.
+	Submissions map[string]grades.AssignmentSubmission
+}
+
+// Identity returns the student's identity on Gradescope. Gradescope accounts
+// are keyed by email, so the email is used as the key when present.
+func (student *gradescopeStudent) Identity() identity {
+	key := student.Email
+	if key == "" {
+		key = student.SID
+	}
+	return identity{
+		Source: "Gradescope",
+		Key:    key,
+		SID:    student.SID,
+		Email:  student.Email,
+		Name:   student.Name,
+	}
+}
+
+// importGrades imports and returns the students described in the Gradescope
+// grades export at the given path. Assignment columns are matched to the given
+// assignments by name. Columns that do not match any assignment and
+// assignments that have no column are reported to stderr. It panics if an
+// assignment's max points on Gradescope differ from the assignment's max score,
+// since the scores would be out of the wrong total.
+func importGrades(path string, assignments map[string]*grades.Assignment) []*gradescopeStudent {
+	reader, err := NewDictReaderFromPath(path)
+	panicIfErr(err)
+
+	// Match the columns to assignments.
+	header := make(map[string]bool, len(reader.Header()))
+	for _, column := range reader.Header() {
+		header[column] = true
+	}
+	unknown := make(map[string]bool)
+	for _, column := range reader.Header() {
+		if gradescopeMetadata[column] {
+			continue
+		}
+		name := column
+		for _, suffix := range gradescopeSuffixes {
+			if strings.HasSuffix(column, suffix) {
+				name = strings.TrimSuffix(column, suffix)
+				break
+			}
+		}
+		if _, ok := assignments[name]; !ok {
+			unknown[name] = true
+		}
+	}
+	for _, name := range sortedKeys(unknown) {
+		warn("Gradescope column %s does not match any assignment", name)
+	}
+	present := make(map[string]bool)
+	absent := make(map[string]bool)
+	for name := range assignments {
+		if header[name] {
+			present[name] = true
+		} else {
+			absent[name] = true
+		}
+	}
+	for _, name := range sortedKeys(absent) {
+		warn("Assignment %s has no column in the Gradescope export", name)
+	}
+
+	students := make([]*gradescopeStudent, 0)
+	for row, err := reader.Read(); err != io.EOF; row, err = reader.Read() {
+		panicIfErr(err)
+
+		name := row[gradescopeName]
+		if !header[gradescopeName] {
+			name = strings.TrimSpace(row[gradescopeFirstName] + " " + row[gradescopeLastName])
+		}
+		student := &gradescopeStudent{
+			SID:         strings.TrimSpace(row[gradescopeSID]),
+			Name:        name,
+			Email:       strings.TrimSpace(row[gradescopeEmail]),
+			Submissions: make(map[string]grades.AssignmentSubmission, len(present)),
+		}
+
+		for assignmentName := range present {
+			assignment := assignments[assignmentName]
+			submission, err := parseGradescopeSubmission(row, assignmentName)
+			if err != nil {
+				panic(fmt.Errorf("Invalid Gradescope entry for %s on %s: %w", student.Name, assignmentName, err))
+			}
+			if maxPoints := strings.TrimSpace(row[assignmentName+gradescopeMaxPoints]); maxPoints != "" {
+				maxScore, err := strconv.ParseFloat(maxPoints, 64)
+				panicIfErr(err)
+				if maxScore != assignment.MaxScore {
+					panic(fmt.Errorf("Assignment %s has %v max points on Gradescope but a max score of %v", assignmentName, maxScore, assignment.MaxScore))
+				}
+			}
+			student.Submissions[assignmentName] = submission
+		}
+
+		students = append(students, student)
+	}
+
+	return students
+}
+
+// parseGradescopeSubmission parses the submission for the assignment with the
+// given name from a row of the Gradescope export. If the export has no status
+// column, the status is inferred from whether the score and submission time
+// are present.
+func parseGradescopeSubmission(row map[string]string, name string) (grades.AssignmentSubmission, error) {
+	var submission grades.AssignmentSubmission
+	var err error
+
+	scoreValue := strings.TrimSpace(row[name])
+	if scoreValue != "" {
+		submission.Score, err = strconv.ParseFloat(scoreValue, 64)
+		if err != nil {
+			return submission, err
+		}
+	}
+
+	submission.Lateness, err = parseLateness(row[name+gradescopeLateness])
+	if err != nil {
+		return submission, err
+	}
+
+	switch status := strings.TrimSpace(row[name+gradescopeStatus]); status {
+	case "Graded":
+		submission.Status = grades.StatusGraded
+	case "Ungraded":
+		submission.Status = grades.StatusUngraded
+	case "Missing":
+		submission.Status = grades.StatusMissing
+	case "":
+		if scoreValue != "" {
+			submission.Status = grades.StatusGraded
+		} else if strings.TrimSpace(row[name+gradescopeSubmissionTime]) != "" {
+			submission.Status = grades.StatusUngraded
+		} else {
+			submission.Status = grades.StatusMissing
+		}
+	default:
+		return submission, errors.New("Unknown submission status " + status)
+	}
+
+	return submission, nil
+}
+
+// parseLateness parses a lateness in the H:M:S format used by Gradescope. The
+// number of hours may exceed 24. An empty string is treated as not late.
+func parseLateness(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return 0, errors.New("Invalid lateness " + value)
+	}
+	units := []time.Duration{time.Hour, time.Minute, time.Second}
+	var lateness time.Duration
+	for i, part := range parts {
+		n, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || n < 0 {
+			return 0, errors.New("Invalid lateness " + value)
+		}
+		lateness += time.Duration(n) * units[i]
+	}
+	return lateness, nil
+}
+
+// sortedKeys returns the keys of the given map in sorted order.
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// warnings is where warn, note and the reconciliation reports print, so that
+// tests can check them.
+var warnings io.Writer = os.Stderr
+
+// warn prints a warning about the inputs to stderr.
+func warn(format string, args ...interface{}) {
+	fmt.Fprintf(warnings, "Warning: "+format+"\n", args...)
+}
+
+// note prints information about how the inputs were applied to stderr.
+func note(format string, args ...interface{}) {
+	fmt.Fprintf(warnings, format+"\n", args...)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cs161-staff/grades"
+)
+
+func TestImportGrades(t *testing.T) {
+	var output bytes.Buffer
+	warnings = &output
+	defer func() {
+		warnings = os.Stderr
+	}()
+
+	assignments := map[string]*grades.Assignment{
+		"HW 1":    {Name: "HW 1", MaxScore: 10},
+		"HW 2":    {Name: "HW 2", MaxScore: 10},
+		"Project": {Name: "Project", MaxScore: 20},
+	}
+	path := writeTemp(t, "grades.csv", `Name,SID,Email,HW 1,HW 1 - Max Points,HW 1 - Submission Time,HW 1 - Lateness (H:M:S),HW 2,HW 2 - Max Points,HW 2 - Submission Time,HW 2 - Lateness (H:M:S),Quiz,Quiz - Max Points
+Alice Smith,3031000001,alice@berkeley.edu,8,10,2021-09-08 23:00:00 -0700,0:00:00,,10,2021-09-16 00:30:00 -0700,1:30:00,1,1
+Bob Jones,3031000002,bob@berkeley.edu,,10,,0:00:00,5.5,10,2021-09-15 22:00:00 -0700,0:00:00,,1
+`)
+	students := importGrades(path, assignments)
+	if len(students) != 2 {
+		t.Fatalf("Got %d students; expected 2", len(students))
+	}
+	alice, bob := students[0].Submissions, students[1].Submissions
+	if len(alice) != 2 {
+		t.Errorf("Got submissions %v; expected only the assignments with columns", alice)
+	}
+	// Without status columns, the status is inferred from the score and
+	// submission time.
+	expected := map[string]grades.AssignmentSubmission{
+		"Alice HW 1": {Score: 8, Status: grades.StatusGraded},
+		"Alice HW 2": {Lateness: 90 * time.Minute, Status: grades.StatusUngraded},
+		"Bob HW 1":   {Status: grades.StatusMissing},
+		"Bob HW 2":   {Score: 5.5, Status: grades.StatusGraded},
+	}
+	got := map[string]grades.AssignmentSubmission{
+		"Alice HW 1": alice["HW 1"],
+		"Alice HW 2": alice["HW 2"],
+		"Bob HW 1":   bob["HW 1"],
+		"Bob HW 2":   bob["HW 2"],
+	}
+	for key, submission := range expected {
+		if got[key].Score != submission.Score || got[key].Lateness != submission.Lateness || got[key].Status != submission.Status {
+			t.Errorf("Got %s submission %+v; expected %+v", key, got[key], submission)
+		}
+	}
+	for _, warning := range []string{"Gradescope column Quiz does not match any assignment", "Assignment Project has no column"} {
+		if !strings.Contains(output.String(), warning) {
+			t.Errorf("Warnings %q do not include %q", output.String(), warning)
+		}
+	}
+
+	expectPanic(t, "Max points that differ from the assignment", func() {
+		importGrades(writeTemp(t, "grades.csv", "Name,SID,Email,HW 1,HW 1 - Max Points\nAlice Smith,3031000001,alice@berkeley.edu,8,20\n"), assignments)
+	})
+}
+
+func TestParseLateness(t *testing.T) {
+	cases := map[string]time.Duration{
+		"":          0,
+		"0:00:00":   0,
+		"01:02:03":  time.Hour + 2*time.Minute + 3*time.Second,
+		"49:00:30":  49*time.Hour + 30*time.Second,
+		" 0:15:00 ": 15 * time.Minute,
+	}
+	for value, expected := range cases {
+		lateness, err := parseLateness(value)
+		if err != nil || lateness != expected {
+			t.Errorf("parseLateness(%q) = %v, %v; expected %v", value, lateness, err, expected)
+		}
+	}
+	for _, value := range []string{"1:00", "a:b:c", "-1:00:00"} {
+		if _, err := parseLateness(value); err == nil {
+			t.Errorf("parseLateness(%q) did not fail", value)
+		}
+	}
+}
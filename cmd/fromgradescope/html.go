@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+
+	"github.com/cs161-staff/grades"
+)
+
+// htmlReportTemplate is the template for a student's HTML grade report. It is
+// self-contained so that it can be sent to students as a single file.
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Grade report for {{.Name}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+th { background: #f0f0f0; }
+td.number { text-align: right; }
+tr.dropped { color: #888; text-decoration: line-through; }
+ul { margin: 0; padding-left: 1.2em; }
+</style>
+</head>
+<body>
+<h1>Grade report for {{.Name}}</h1>
+<p>SID: {{.SID}}</p>
+<p>Total: {{.Total}}%{{if .Letter}} ({{.Letter}}){{end}}</p>
+{{if and .Grade (ne .Grade .Letter)}}<p>Grade ({{.GradingBasis}}): {{.Grade}}</p>{{end}}
+<p>Slip days used: {{.SlipDaysUsed}}</p>
+{{if .Comments}}<ul>{{range .Comments}}<li>{{.}}</li>{{end}}</ul>{{end}}
+<h2>Categories</h2>
+<table>
+<tr><th>Category</th><th>Weight</th><th>Drops</th><th>Raw</th><th>Adjusted</th><th>Weighted</th><th>Comments</th></tr>
+{{range .Categories}}<tr>
+<td>{{.Name}}</td>
+<td class="number">{{.Weight}}%</td>
+<td class="number">{{.Drops}}</td>
+<td class="number">{{.Raw}}%</td>
+<td class="number">{{.Adjusted}}%</td>
+<td class="number">{{.Weighted}}%</td>
+<td>{{if .Comments}}<ul>{{range .Comments}}<li>{{.}}</li>{{end}}</ul>{{end}}</td>
+</tr>
+{{end}}</table>
+<h2>Assignments</h2>
+<table>
+<tr><th>Assignment</th><th>Category</th><th>Score</th><th>Raw</th><th>Multipliers</th><th>Adjusted</th><th>Weighted</th><th>Slip days</th><th>Comments</th></tr>
+{{range .Assignments}}<tr{{if .Dropped}} class="dropped"{{end}}>
+<td>{{.Name}}{{if .Dropped}} (dropped){{end}}</td>
+<td>{{.Category}}</td>
+<td class="number">{{.Score}} / {{.MaxScore}}</td>
+<td class="number">{{.Raw}}%</td>
+<td>{{if .Multipliers}}<ul>{{range .Multipliers}}<li>x{{.Factor}} ({{.Description}})</li>{{end}}</ul>{{end}}</td>
+<td class="number">{{.Adjusted}}%</td>
+<td class="number">{{.Weighted}}%</td>
+<td class="number">{{.SlipDays}}</td>
+<td>{{if .Comments}}<ul>{{range .Comments}}<li>{{.}}</li>{{end}}</ul>{{end}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// exportHTML writes an HTML grade report for each student to the given
+// directory, named by SID.
+func exportHTML(dir string, reports map[int]*grades.GradeReport, categories map[string]*grades.Category, assignments map[string]*grades.Assignment, rounding int) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	categoryNames, assignmentNames := exportColumns(categories, assignments)
+	for _, sid := range sortedSIDs(reports) {
+		file, err := os.Create(filepath.Join(dir, fmt.Sprintf("%d.html", sid)))
+		if err != nil {
+			return err
+		}
+		err = htmlReportTemplate.Execute(file, newReportView(reports[sid], categoryNames, assignmentNames, rounding))
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cs161-staff/grades"
+)
+
+func TestExportHTML(t *testing.T) {
+	categories, assignments := testCourse()
+	first := testReport(3031000001, "Smith <Alice> & Co", 0.9, "A-", "A-")
+	first.Categories["Homework"].Comments = []string{"<script>alert(1)</script>"}
+	reports := map[int]*grades.GradeReport{
+		3031000001: first,
+		3031000002: testReport(3031000002, "Jones, Bob", 0.8, "B-", "B-"),
+	}
+	dir := filepath.Join(t.TempDir(), "html")
+	if err := exportHTML(dir, reports, categories, assignments, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[0].Name() != "3031000001.html" || files[1].Name() != "3031000002.html" {
+		t.Fatalf("Got files %v; expected a page for each student", files)
+	}
+	contents, err := os.ReadFile(filepath.Join(dir, "3031000001.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(contents)
+	for _, expected := range []string{
+		"<h1>Grade report for Smith &lt;Alice&gt; &amp; Co</h1>",
+		"<li>&lt;script&gt;alert(1)&lt;/script&gt;</li>",
+		"<p>Total: 90.0% (A-)</p>",
+	} {
+		if !strings.Contains(page, expected) {
+			t.Errorf("Page does not contain %q", expected)
+		}
+	}
+	if strings.Contains(page, "<script>") {
+		t.Error("Page contains an unescaped comment")
+	}
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Column names used in the identity mapping file.
+const (
+	mappingSource = "Source"
+	mappingKey    = "Key"
+	mappingName   = "Name"
+	mappingEmail  = "Email"
+	mappingSID    = "SID"
+	mappingRule   = "Rule"
+	mappingStatus = "Status"
+)
+
+// manualRule is the rule name recorded for matches taken from the mapping
+// file.
+const manualRule = "manual"
+
+// identity is a student's identifying information as it appears in one of the
+// data sources.
+type identity struct {
+	// Source is the name of the data source, such as "Gradescope".
+	Source string
+
+	// Key identifies the row within the source. It is used to look up manual
+	// matches in the mapping file, so it should be stable across exports.
+	Key string
+
+	// SID is the student ID as written in the source, which may be invalid.
+	SID string
+
+	// Email is the email address in the source, if any.
+	Email string
+
+	// Name is the name in the source, if any.
+	Name string
+}
+
+// identityMatch is the result of resolving an identity against the roster.
+type identityMatch struct {
+	// SID is the roster SID the identity resolved to, or 0 if it did not
+	// resolve.
+	SID int
+
+	// Rule is the name of the rule that produced the match.
+	Rule string
+
+	// Candidates is the roster SIDs that the identity could belong to when the
+	// match is ambiguous.
+	Candidates []int
+}
+
+// Ambiguous returns whether the identity matched more than one student.
+func (match identityMatch) Ambiguous() bool {
+	return len(match.Candidates) > 1
+}
+
+// matchRule returns the roster SIDs that an identity could belong to.
+type matchRule func(resolver *identityResolver, id identity) []int
+
+// matchRules is the available match rules by name.
+var matchRules = map[string]matchRule{
+	// sid matches the SID exactly.
+	"sid": func(resolver *identityResolver, id identity) []int {
+		sid, err := strconv.Atoi(strings.TrimSpace(id.SID))
+		if err != nil || !resolver.sids[sid] {
+			return nil
+		}
+		return []int{sid}
+	},
+	// sid-typo matches SIDs with a single mistyped, missing, extra or swapped
+	// digit.
+	"sid-typo": func(resolver *identityResolver, id identity) []int {
+		sid := strings.TrimSpace(id.SID)
+		if sid == "" {
+			return nil
+		}
+		candidates := make([]int, 0)
+		for candidate := range resolver.sids {
+			if editDistance(sid, strconv.Itoa(candidate)) <= 1 {
+				candidates = append(candidates, candidate)
+			}
+		}
+		return candidates
+	},
+	// email matches the email address, ignoring case.
+	"email": func(resolver *identityResolver, id identity) []int {
+		return resolver.emails[normalizeEmail(id.Email)]
+	},
+	// name matches the normalized name.
+	"name": func(resolver *identityResolver, id identity) []int {
+		return resolver.names[normalizeName(id.Name)]
+	},
+}
+
+// identityResolver resolves identities from the data sources to students on the
+// roster. Each rule is tried in order and the first rule that finds any
+// candidates decides the match. A match is ambiguous if that rule finds more
+// than one candidate, or if a later rule uniquely finds a different student.
+// Ambiguous identities are never matched automatically; they are recorded in
+// the mapping file for staff to resolve by hand.
+type identityResolver struct {
+	ruleNames []string
+	sids      map[int]bool
+	emails    map[string][]int
+	names     map[string][]int
+	manual    map[string]int
+	resolved  map[string]identity
+	matches   map[string]identityMatch
+}
+
+// newIdentityResolver returns a resolver for the given roster entries that
+// uses the match rules with the given names, in order.
+func newIdentityResolver(entries []*rosterEntry, ruleNames []string) (*identityResolver, error) {
+	for _, name := range ruleNames {
+		if _, ok := matchRules[name]; !ok {
+			return nil, errors.New("Unknown match rule " + name)
+		}
+	}
+	resolver := &identityResolver{
+		ruleNames: ruleNames,
+		sids:      make(map[int]bool, len(entries)),
+		emails:    make(map[string][]int, len(entries)),
+		names:     make(map[string][]int, len(entries)),
+		manual:    make(map[string]int),
+		resolved:  make(map[string]identity),
+		matches:   make(map[string]identityMatch),
+	}
+	for _, entry := range entries {
+		student := entry.Student
+		resolver.sids[student.SID] = true
+		if email := normalizeEmail(student.Email); email != "" {
+			resolver.emails[email] = append(resolver.emails[email], student.SID)
+		}
+		if name := normalizeName(student.Name); name != "" {
+			resolver.names[name] = append(resolver.names[name], student.SID)
+		}
+	}
+	return resolver, nil
+}
+
+// Resolve returns the match for the given identity and records it for the
+// mapping file.
+func (resolver *identityResolver) Resolve(id identity) identityMatch {
+	key := mappingFileKey(id.Source, id.Key)
+	resolver.resolved[key] = id
+	if sid, ok := resolver.manual[key]; ok {
+		match := identityMatch{SID: sid, Rule: manualRule}
+		resolver.matches[key] = match
+		return match
+	}
+
+	var match identityMatch
+	for _, name := range resolver.ruleNames {
+		candidates := uniqueSorted(matchRules[name](resolver, id))
+		if len(candidates) == 0 {
+			continue
+		}
+		if match.Rule == "" {
+			match.Rule = name
+			match.Candidates = candidates
+			if len(candidates) == 1 {
+				match.SID = candidates[0]
+			}
+		} else if match.SID != 0 && len(candidates) == 1 && candidates[0] != match.SID {
+			// A later rule disagrees with the deciding rule.
+			match.Candidates = uniqueSorted(append(match.Candidates, candidates[0]))
+			match.SID = 0
+		}
+	}
+	if match.Ambiguous() {
+		match.SID = 0
+	}
+	resolver.matches[key] = match
+	return match
+}
+
+// Reject records that the identity's match was rejected, such as because
+// another identity matched the same student exactly, so that the identity is
+// written to the mapping file without a SID for staff to resolve.
+func (resolver *identityResolver) Reject(id identity) {
+	key := mappingFileKey(id.Source, id.Key)
+	resolver.matches[key] = identityMatch{Rule: resolver.matches[key].Rule}
+}
+
+// ResolveRow resolves the student in a row of one of the CSV inputs, which has
+// a SID column and optional Email and Name columns. The row is keyed by its
+// email address, or its SID if it has none, like Gradescope students. It
+// warns and returns 0 if the row does not match exactly one student.
+func (resolver *identityResolver) ResolveRow(source string, row map[string]string) int {
+	id := identity{
+		Source: source,
+		SID:    strings.TrimSpace(row["SID"]),
+		Email:  strings.TrimSpace(row["Email"]),
+		Name:   strings.TrimSpace(row["Name"]),
+	}
+	id.Key = id.Email
+	if id.Key == "" {
+		id.Key = id.SID
+	}
+	match := resolver.Resolve(id)
+	if match.Ambiguous() {
+		candidates := make([]string, len(match.Candidates))
+		for i, sid := range match.Candidates {
+			candidates[i] = strconv.Itoa(sid)
+		}
+		warn("%s row for SID %q matches more than one student (%s); resolve it in the identities file", source, id.SID, strings.Join(candidates, ", "))
+	} else if match.SID == 0 {
+		warn("%s row for SID %q does not match any student on the roster", source, id.SID)
+	}
+	return match.SID
+}
+
+// LoadMapping loads the manual matches from the mapping file at the given path.
+// Every row of the file with a SID is treated as a manual match. A missing
+// file is not an error.
+func (resolver *identityResolver) LoadMapping(path string) error {
+	reader, err := NewDictReaderFromPath(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for row, err := reader.Read(); err != io.EOF; row, err = reader.Read() {
+		if err != nil {
+			return err
+		}
+		sidValue := strings.TrimSpace(row[mappingSID])
+		if sidValue == "" {
+			continue
+		}
+		sid, err := strconv.Atoi(sidValue)
+		if err != nil {
+			return fmt.Errorf("Invalid SID in mapping file: %w", err)
+		}
+		if !resolver.sids[sid] {
+			return fmt.Errorf("Mapping file references SID %d, which is not on the roster", sid)
+		}
+		resolver.manual[mappingFileKey(row[mappingSource], row[mappingKey])] = sid
+	}
+	return nil
+}
+
+// WriteMapping writes every resolved identity and its match to a mapping file
+// at the given path. Ambiguous and unmatched identities are written with an
+// empty SID so staff can fill them in.
+func (resolver *identityResolver) WriteMapping(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	keys := make([]string, 0, len(resolver.resolved))
+	for key := range resolver.resolved {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{mappingSource, mappingKey, mappingName, mappingEmail, mappingSID, mappingRule, mappingStatus})
+	for _, key := range keys {
+		id := resolver.resolved[key]
+		match := resolver.matches[key]
+		var sid, status string
+		switch {
+		case match.SID != 0:
+			sid = strconv.Itoa(match.SID)
+			status = "matched"
+		case match.Ambiguous():
+			candidates := make([]string, len(match.Candidates))
+			for i, candidate := range match.Candidates {
+				candidates[i] = strconv.Itoa(candidate)
+			}
+			status = "ambiguous: " + strings.Join(candidates, " ")
+		default:
+			status = "unmatched"
+		}
+		writer.Write([]string{id.Source, id.Key, id.Name, id.Email, sid, match.Rule, status})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// mappingFileKey returns the key for a row of the mapping file.
+func mappingFileKey(source string, key string) string {
+	return source + "\x00" + key
+}
+
+// normalizeEmail normalizes an email address for comparison.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// normalizeName normalizes a name for comparison by lowercasing it, removing
+// punctuation, and sorting its words, so that "Last, First" and "First Last"
+// are equal.
+func normalizeName(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	for i, word := range words {
+		words[i] = strings.ReplaceAll(word, "'", "")
+	}
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+// editDistance returns the optimal string alignment distance between a and b,
+// which counts insertions, deletions, substitutions and adjacent
+// transpositions.
+func editDistance(a string, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, minInt(d[i][j-1]+1, d[i-1][j-1]+cost))
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+// minInt returns the smaller of a and b.
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// uniqueSorted returns the unique elements of sids in sorted order.
+func uniqueSorted(sids []int) []int {
+	seen := make(map[int]bool, len(sids))
+	unique := make([]int, 0, len(sids))
+	for _, sid := range sids {
+		if !seen[sid] {
+			seen[sid] = true
+			unique = append(unique, sid)
+		}
+	}
+	sort.Ints(unique)
+	return unique
+}
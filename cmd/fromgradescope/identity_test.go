@@ -0,0 +1,85 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cs161-staff/grades"
+)
+
+func TestResolve(t *testing.T) {
+	entries := []*rosterEntry{
+		{Student: &grades.Student{SID: 3031000001, Name: "Smith, Alice", Email: "alice@berkeley.edu"}},
+		{Student: &grades.Student{SID: 3031000002, Name: "Jones, Bob", Email: "bob@berkeley.edu"}},
+		{Student: &grades.Student{SID: 3031000012, Name: "Jones, Bob", Email: "bobby@berkeley.edu"}},
+	}
+	resolver, err := newIdentityResolver(entries, []string{"sid", "sid-typo", "email", "name"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		id       identity
+		expected identityMatch
+	}{
+		{identity{SID: "3031000001"}, identityMatch{SID: 3031000001, Rule: "sid", Candidates: []int{3031000001}}},
+		{identity{SID: "3031000091", Email: "alice@berkeley.edu"}, identityMatch{SID: 3031000001, Rule: "sid-typo", Candidates: []int{3031000001}}},
+		// A missing digit could belong to either student.
+		{identity{SID: "303100001"}, identityMatch{Rule: "sid-typo", Candidates: []int{3031000001, 3031000012}}},
+		{identity{Email: "ALICE@berkeley.edu"}, identityMatch{SID: 3031000001, Rule: "email", Candidates: []int{3031000001}}},
+		{identity{Name: "Alice Smith"}, identityMatch{SID: 3031000001, Rule: "name", Candidates: []int{3031000001}}},
+		// Both Bobs have the same name.
+		{identity{Name: "Bob Jones"}, identityMatch{Rule: "name", Candidates: []int{3031000002, 3031000012}}},
+		// The SID belongs to one student but the email to another.
+		{identity{SID: "3031000002", Email: "bobby@berkeley.edu"}, identityMatch{Rule: "sid", Candidates: []int{3031000002, 3031000012}}},
+		{identity{SID: "123", Email: "eve@berkeley.edu", Name: "Eve"}, identityMatch{}},
+	}
+	for i, c := range cases {
+		c.id.Key = string(rune('a' + i))
+		match := resolver.Resolve(c.id)
+		if !reflect.DeepEqual(match, c.expected) {
+			t.Errorf("Resolve(%+v) = %+v; expected %+v", c.id, match, c.expected)
+		}
+	}
+}
+
+func TestResolveRow(t *testing.T) {
+	entries := []*rosterEntry{
+		{Student: &grades.Student{SID: 3031000001, Name: "Smith, Alice", Email: "alice@berkeley.edu"}},
+	}
+	resolver, err := newIdentityResolver(entries, []string{"sid", "sid-typo", "email"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := []map[string]string{
+		{"SID": "3031000001"},
+		{"SID": "3031000091"},
+		{"SID": "", "Email": "Alice@Berkeley.edu"},
+	}
+	for _, row := range rows {
+		if sid := resolver.ResolveRow("Extensions", row); sid != 3031000001 {
+			t.Errorf("ResolveRow(%v) = %d; expected 3031000001", row, sid)
+		}
+	}
+	if sid := resolver.ResolveRow("Extensions", map[string]string{"SID": "123"}); sid != 0 {
+		t.Errorf("ResolveRow matched an unknown SID to %d", sid)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"3031000001", "3031000001", 0},
+		{"3031000001", "3031000002", 1},
+		{"3031000001", "303100001", 1},
+		{"3031000001", "3031000010", 1},
+		{"3031000001", "3031000022", 2},
+	}
+	for _, c := range cases {
+		if distance := editDistance(c.a, c.b); distance != c.expected {
+			t.Errorf("editDistance(%q, %q) = %d; expected %d", c.a, c.b, distance, c.expected)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/cs161-staff/grades"
+)
+
+// importIncompletes imports the CSV of Incomplete grades at the given path,
+// with SID and Assignment columns, optional Email and Name columns and one row
+// per pending assignment, and returns the pending assignments of
+// each student by SID. Rows are matched to students with the resolver.
+func importIncompletes(path string, assignments map[string]*grades.Assignment, resolver *identityResolver) map[int][]string {
+	reader, err := NewDictReaderFromPath(path)
+	panicIfErr(err)
+
+	pending := make(map[int][]string)
+	for row, err := reader.Read(); err != io.EOF; row, err = reader.Read() {
+		panicIfErr(err)
+
+		name := strings.TrimSpace(row["Assignment"])
+		if _, ok := assignments[name]; !ok {
+			panic(fmt.Errorf("Unknown assignment in incompletes: %s", name))
+		}
+		sid := resolver.ResolveRow("Incompletes", row)
+		if sid == 0 {
+			continue
+		}
+		pending[sid] = append(pending[sid], name)
+	}
+	return pending
+}
+
+// applyIncompletes marks each student's pending assignments as pending.
+// Students who are not on the roster are reported and skipped, as are graded
+// assignments, since their scores have already arrived.
+func applyIncompletes(roster grades.Roster, pending map[int][]string) {
+	sids := make([]int, 0, len(pending))
+	for sid := range pending {
+		sids = append(sids, sid)
+	}
+	sort.Ints(sids)
+	for _, sid := range sids {
+		outcomes, ok := roster[sid]
+		if !ok {
+			warn("SID %d in incompletes is not on the roster", sid)
+			continue
+		}
+		for _, name := range pending[sid] {
+			if outcomes[0].Assignments[name].Grade.Status == grades.StatusGraded {
+				warn("SID %d is listed in incompletes for %s, which is already graded; keeping the score", sid, name)
+				continue
+			}
+			for _, student := range outcomes {
+				student.Assignments[name].Grade.Status = grades.StatusPending
+			}
+		}
+	}
+}
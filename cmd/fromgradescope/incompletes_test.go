@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/cs161-staff/grades"
+)
+
+func TestApplyIncompletes(t *testing.T) {
+	categories := map[string]*grades.Category{"Homework": {Name: "Homework", Weight: 1}}
+	assignments := map[string]*grades.Assignment{
+		"HW 1": {Name: "HW 1", CategoryName: "Homework", MaxScore: 10, Weight: 1},
+		"HW 2": {Name: "HW 2", CategoryName: "Homework", MaxScore: 10, Weight: 1},
+	}
+	student := grades.NewStudent(1, "Student", categories, assignments, map[string]grades.AssignmentSubmission{
+		"HW 1": {Score: 8, Status: grades.StatusGraded},
+		"HW 2": {Status: grades.StatusMissing},
+	})
+	roster := grades.Roster{1: {student}}
+
+	applyIncompletes(roster, map[int][]string{1: {"HW 1", "HW 2"}, 2: {"HW 1"}})
+	if status := student.Assignments["HW 1"].Grade.Status; status != grades.StatusGraded {
+		t.Errorf("Graded assignment has status %v after applying incompletes", status)
+	}
+	if status := student.Assignments["HW 2"].Grade.Status; status != grades.StatusPending {
+		t.Errorf("Missing assignment has status %v after applying incompletes; expected pending", status)
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cs161-staff/grades"
+)
+
+// inputs is the command-line flags describing the data sources and the
+// grading configuration, shared by every subcommand.
+type inputs struct {
+	// Mandatory args.
+	rosterPath      string
+	gradesPath      string
+	configPath      string
+	categoriesPath  string
+	assignmentsPath string
+
+	// Optional args.
+	overridesPath      string
+	clobbersPath       string
+	extensionsPath     string
+	accommodationsPath string
+	incompletesPath    string
+	identitiesPath     string
+	matchRuleNames     string
+	binsPath           string
+	lateScale          string
+	lateGrace          time.Duration
+	canvasPath         string
+	canvasCategory     string
+	cachePath          string
+}
+
+// register registers the input flags with the flag set.
+func (in *inputs) register(flags *flag.FlagSet) {
+	flags.StringVar(&in.rosterPath, "roster", "", "CSV roster downloaded from CalCentral")
+	flags.StringVar(&in.gradesPath, "grades", "", "CSV grades downloaded from Gradescope")
+	flags.StringVar(&in.configPath, "config", "", "JSON course configuration, used instead of -categories, -assignments, -bins and -late-*")
+	flags.StringVar(&in.categoriesPath, "categories", "", "CSV with assignment categories")
+	flags.StringVar(&in.assignmentsPath, "assignments", "", "CSV with assignments")
+
+	flags.StringVar(&in.overridesPath, "overrides", "", "CSV with SID, Assignment and Score columns overriding students' scores")
+	flags.StringVar(&in.clobbersPath, "clobbers", "", "CSV with Source, Target and optional Style (scaled or zscore) columns clobbering one assignment's score with another's")
+	flags.StringVar(&in.extensionsPath, "extensions", "", "CSV with SID, Assignment and Extension columns, where an extension is days, a duration, a percentage of the assignment's window or a new deadline in RFC 3339 or Gradescope's time format")
+	flags.StringVar(&in.accommodationsPath, "accommodations", "", "CSV with accommodations for drops, slip days and extensions, one row per student and category")
+	flags.StringVar(&in.incompletesPath, "incompletes", "", "CSV with SID and Assignment columns listing the pending assignments of students with an Incomplete")
+	flags.StringVar(&in.identitiesPath, "identities", "", "CSV mapping students across data sources; read for manual matches and rewritten with the resolved matches")
+	flags.StringVar(&in.matchRuleNames, "match-rules", "sid,email,name", "Comma-separated rules to match students across data sources, in order (sid, sid-typo, email, name)")
+	flags.StringVar(&in.binsPath, "bins", "", "CSV with grade bins (default standard absolute scale)")
+	flags.StringVar(&in.lateScale, "late-scale", "", "Comma-separated late multipliers for assignments 1, 2, ... days late")
+	flags.DurationVar(&in.lateGrace, "late-grace", 0, "Grace period before an assignment is considered late")
+	flags.StringVar(&in.canvasPath, "canvas", "", "CSV gradebook downloaded from Canvas")
+	flags.StringVar(&in.canvasCategory, "canvas-category", "", "Category for Canvas assignments that are not in the assignments CSV")
+	flags.StringVar(&in.cachePath, "cache", "", "Cache file of grade reports, so that only students whose inputs changed are recomputed")
+}
+
+// valid returns whether all mandatory inputs are present. Either a course
+// configuration or both the categories and assignments CSVs are required.
+func (in *inputs) valid() bool {
+	return in.rosterPath != "" && in.gradesPath != "" && (in.configPath != "" || (in.categoriesPath != "" && in.assignmentsPath != ""))
+}
+
+// computation is the result of computing grades from the inputs.
+type computation struct {
+	// course is the course configuration, including any assignments added
+	// from Canvas.
+	course *grades.CourseConfig
+
+	// rosterEntries is the enrolled and waitlisted students on the roster.
+	rosterEntries []*rosterEntry
+
+	// canvasStudents is the students on the Canvas gradebook by SID.
+	canvasStudents map[int]*canvasGradebookStudent
+
+	// roster is the roster before the course's pipeline was applied.
+	roster grades.Roster
+
+	// reports is the finalized grade report of each student by SID, with
+	// letter grades assigned.
+	reports map[int]*grades.GradeReport
+}
+
+// compute imports the inputs, applies the course's pipeline and finalizes each
+// student's grade report, using the cache file if there is one.
+func compute(in *inputs) *computation {
+	return computeCached(in, in.loadCache())
+}
+
+// loadCache loads the cache file, or returns nil if there is none.
+func (in *inputs) loadCache() *grades.Cache {
+	if in.cachePath == "" {
+		return nil
+	}
+	cache, err := grades.LoadCache(in.cachePath)
+	panicIfErr(err)
+	return cache
+}
+
+// computeCached is like compute, but uses the given cache, which may be nil.
+// The cache is saved to the cache file if there is one.
+func computeCached(in *inputs, cache *grades.Cache) *computation {
+	var course *grades.CourseConfig
+	var err error
+	if in.configPath != "" {
+		if in.categoriesPath != "" || in.assignmentsPath != "" || in.binsPath != "" || in.lateScale != "" || in.lateGrace != 0 {
+			panic(errors.New("-categories, -assignments, -bins, -late-scale and -late-grace cannot be combined with -config; set them in the course configuration instead"))
+		}
+		course, err = grades.LoadCourseConfig(in.configPath)
+		panicIfErr(err)
+	} else {
+		course = importCourse(in.categoriesPath, in.assignmentsPath, in.binsPath, in.lateScale, in.lateGrace)
+	}
+	categories := course.Categories
+	assignments := course.Assignments
+	rosterEntries := importRoster(in.rosterPath)
+	resolver, err := newIdentityResolver(rosterEntries, strings.Split(in.matchRuleNames, ","))
+	panicIfErr(err)
+	if in.identitiesPath != "" {
+		panicIfErr(resolver.LoadMapping(in.identitiesPath))
+	}
+	submissions := make(map[int]map[string]grades.AssignmentSubmission)
+	gradescopeStudents := importGrades(in.gradesPath, assignments)
+	gradescopeIdentities := make([]identity, len(gradescopeStudents))
+	for i, student := range gradescopeStudents {
+		gradescopeIdentities[i] = student.Identity()
+	}
+	for sid, i := range reconcile("Gradescope", rosterEntries, gradescopeIdentities, resolver) {
+		mergeSubmissions(submissions, sid, "Gradescope", gradescopeStudents[i].Submissions)
+	}
+	canvasStudents := make(map[int]*canvasGradebookStudent)
+	if in.canvasPath != "" {
+		canvasGradebook, canvasAssignments := importCanvas(in.canvasPath, assignments, categories, in.canvasCategory)
+		if len(canvasAssignments) > 0 {
+			// Add the Canvas assignments to a copy of the course, so that the
+			// loaded course configuration is not changed. They are new, so they
+			// cannot conflict with the course's assignments or requirements.
+			assignments = make(map[string]*grades.Assignment, len(course.Assignments)+len(canvasAssignments))
+			for name, assignment := range course.Assignments {
+				assignments[name] = assignment
+			}
+			for name, assignment := range canvasAssignments {
+				assignments[name] = assignment
+			}
+			withCanvas := *course
+			withCanvas.Assignments = assignments
+			course = &withCanvas
+		}
+		canvasIdentities := make([]identity, len(canvasGradebook))
+		for i, student := range canvasGradebook {
+			canvasIdentities[i] = student.Identity()
+		}
+		for sid, i := range reconcile("Canvas", rosterEntries, canvasIdentities, resolver) {
+			canvasStudents[sid] = canvasGradebook[i]
+			mergeSubmissions(submissions, sid, "Canvas", canvasGradebook[i].Submissions)
+		}
+	}
+	roster := buildRoster(rosterEntries, submissions, categories, assignments)
+	if in.incompletesPath != "" {
+		applyIncompletes(roster, importIncompletes(in.incompletesPath, assignments, resolver))
+	}
+	if in.extensionsPath != "" {
+		applyExtensions(course, roster, importExtensions(in.extensionsPath, assignments, resolver))
+	}
+	if in.accommodationsPath != "" {
+		applyAccommodations(course, roster, importAccommodations(in.accommodationsPath, categories, resolver))
+	}
+	if in.overridesPath != "" {
+		applyOverrides(course, roster, importOverrides(in.overridesPath, assignments, resolver))
+	}
+	if in.clobbersPath != "" {
+		applyClobbers(course, importClobbers(in.clobbersPath, assignments))
+	}
+	if in.identitiesPath != "" {
+		panicIfErr(resolver.WriteMapping(in.identitiesPath))
+	}
+
+	reports, err := finalize(course, course.Pipeline, roster, cache)
+	panicIfErr(err)
+	if cache != nil {
+		if in.cachePath != "" {
+			panicIfErr(cache.Save(in.cachePath))
+		}
+		fmt.Fprintf(os.Stderr, "Reused %d cached grade reports and recomputed %d\n", cache.Hits, cache.Misses)
+	}
+
+	return &computation{
+		course:         course,
+		rosterEntries:  rosterEntries,
+		canvasStudents: canvasStudents,
+		roster:         roster,
+		reports:        reports,
+	}
+}
+
+// finalize applies the pipeline to the roster and returns each student's
+// finalized grade report with a letter grade assigned by the course. If the
+// cache is not nil, it is used to skip students whose inputs have not changed.
+func finalize(course *grades.CourseConfig, pipeline grades.Pipeline, roster grades.Roster, cache *grades.Cache) (map[int]*grades.GradeReport, error) {
+	var reports map[int]*grades.GradeReport
+	if cache != nil {
+		var err error
+		reports, err = cache.Finalize(pipeline, roster)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		roster, err := pipeline.Apply(roster)
+		if err != nil {
+			return nil, err
+		}
+		reports = roster.Finalize()
+	}
+	for _, report := range reports {
+		if err := course.AssignGrade(report); err != nil {
+			return nil, err
+		}
+	}
+	return reports, nil
+}
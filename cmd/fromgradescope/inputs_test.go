@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestComputeFlagsWithConfig(t *testing.T) {
+	for _, in := range []*inputs{
+		{categoriesPath: "categories.csv"},
+		{assignmentsPath: "assignments.csv"},
+		{categoriesPath: "categories.csv", assignmentsPath: "assignments.csv"},
+		{binsPath: "bins.csv"},
+		{lateScale: "0.9"},
+	} {
+		in.rosterPath = "roster.csv"
+		in.gradesPath = "grades.csv"
+		in.configPath = "course.json"
+		if !in.valid() {
+			t.Errorf("Inputs %+v rejected before computing", in)
+			continue
+		}
+		func() {
+			defer func() {
+				err, ok := recover().(error)
+				if !ok || !strings.Contains(err.Error(), "cannot be combined with -config") {
+					t.Errorf("Inputs %+v got %v; expected them to be rejected with -config", in, err)
+				}
+			}()
+			computeCached(in, nil)
+		}()
+	}
+
+	if in := (&inputs{rosterPath: "roster.csv", gradesPath: "grades.csv", categoriesPath: "categories.csv"}); in.valid() {
+		t.Error("Categories without assignments accepted")
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cs161-staff/grades"
+	"github.com/cs161-staff/grades/policies/extensions"
+)
+
+// whatIfFlag is a repeatable flag of name=value pairs.
+type whatIfFlag []string
+
+func (values *whatIfFlag) String() string {
+	return strings.Join(*values, ", ")
+}
+
+func (values *whatIfFlag) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("Expected name=value, got %q", value)
+	}
+	*values = append(*values, value)
+	return nil
+}
+
+// parse parses the values of the flag, checking that each name is in names and
+// has at most one value.
+func (values whatIfFlag) parse(names map[string]bool, kind string) (map[string]float64, error) {
+	parsed := make(map[string]float64, len(values))
+	for _, value := range values {
+		separator := strings.LastIndex(value, "=")
+		name := value[:separator]
+		if !names[name] {
+			return nil, fmt.Errorf("Unknown %s %s", kind, name)
+		}
+		if _, ok := parsed[name]; ok {
+			return nil, fmt.Errorf("More than one value for %s %s", kind, name)
+		}
+		number, err := strconv.ParseFloat(value[separator+1:], 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value for %s %s: %w", kind, name, err)
+		}
+		parsed[name] = number
+	}
+	return parsed, nil
+}
+
+// parseExtensions parses the values of the flag as extensions, checking that
+// each name is in names and has at most one extension.
+func (values whatIfFlag) parseExtensions(names map[string]bool) (map[string]extensions.Extension, error) {
+	parsed := make(map[string]extensions.Extension, len(values))
+	for _, value := range values {
+		separator := strings.LastIndex(value, "=")
+		name := value[:separator]
+		if !names[name] {
+			return nil, fmt.Errorf("Unknown assignment %s", name)
+		}
+		if _, ok := parsed[name]; ok {
+			return nil, fmt.Errorf("More than one extension for assignment %s", name)
+		}
+		extension, err := extensions.ParseExtension(value[separator+1:])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid extension for assignment %s: %w", name, err)
+		}
+		parsed[name] = extension
+	}
+	return parsed, nil
+}
+
+// whatIfStages returns pipeline stages that apply the what-if changes to the
+// student with the given SID, in the order to insert them into a pipeline
+// with Pipeline.Insert.
+func whatIfStages(sid int, course *grades.CourseConfig, extensionValues whatIfFlag, drops whatIfFlag, slipDays whatIfFlag, overrides whatIfFlag) (grades.Pipeline, error) {
+	categoryNames := make(map[string]bool, len(course.Categories))
+	for name := range course.Categories {
+		categoryNames[name] = true
+	}
+	assignmentNames := make(map[string]bool, len(course.Assignments))
+	for name := range course.Assignments {
+		assignmentNames[name] = true
+	}
+
+	stages := make(grades.Pipeline, 0)
+	appendStage := func(policy string, studentParams interface{}) error {
+		params, err := json.Marshal(map[int]interface{}{sid: studentParams})
+		if err != nil {
+			return err
+		}
+		stages = append(stages, grades.Stage{Name: policy, Params: params})
+		return nil
+	}
+	addStage := func(policy string, values whatIfFlag, names map[string]bool, kind string, integer bool) error {
+		if len(values) == 0 {
+			return nil
+		}
+		parsed, err := values.parse(names, kind)
+		if err != nil {
+			return err
+		}
+		if !integer {
+			return appendStage(policy, parsed)
+		}
+		integers := make(map[string]int, len(parsed))
+		for name, value := range parsed {
+			if value != float64(int(value)) {
+				return fmt.Errorf("Value for %s %s must be a whole number", kind, name)
+			}
+			integers[name] = int(value)
+		}
+		return appendStage(policy, integers)
+	}
+	if len(extensionValues) > 0 {
+		parsed, err := extensionValues.parseExtensions(assignmentNames)
+		if err != nil {
+			return nil, err
+		}
+		if err := appendStage("extensions", parsed); err != nil {
+			return nil, err
+		}
+	}
+	if err := addStage("changedrops", drops, categoryNames, "category", true); err != nil {
+		return nil, err
+	}
+	if err := addStage("changeslipdays", slipDays, categoryNames, "category", true); err != nil {
+		return nil, err
+	}
+	if err := addStage("overrides", overrides, assignmentNames, "assignment", false); err != nil {
+		return nil, err
+	}
+	return stages, nil
+}
+
+// lookupStudent returns the SID of the enrolled student matching the query,
+// which is a SID, email address or name.
+func lookupStudent(query string, entries []*rosterEntry, ruleNames []string) (int, error) {
+	resolver, err := newIdentityResolver(entries, ruleNames)
+	if err != nil {
+		return 0, err
+	}
+	id := identity{Source: "lookup", Key: query}
+	if _, err := strconv.Atoi(query); err == nil {
+		id.SID = query
+	} else if strings.Contains(query, "@") {
+		id.Email = query
+	} else {
+		id.Name = query
+	}
+	match := resolver.Resolve(id)
+	if match.Ambiguous() {
+		candidates := make([]string, len(match.Candidates))
+		for i, sid := range match.Candidates {
+			candidates[i] = strconv.Itoa(sid)
+		}
+		return 0, fmt.Errorf("%q matches more than one student: %s", query, strings.Join(candidates, ", "))
+	}
+	if match.SID == 0 {
+		return 0, fmt.Errorf("No enrolled student matches %q", query)
+	}
+	return match.SID, nil
+}
+
+// printReport prints a student's finalized report as text.
+func printReport(writer io.Writer, view *reportView) {
+	fmt.Fprintf(writer, "%d %s", view.SID, view.Name)
+	if view.Email != "" {
+		fmt.Fprintf(writer, " <%s>", view.Email)
+	}
+	fmt.Fprintf(writer, "\nTotal: %s%% (%s)\nGrade (%s): %s\nSlip days used: %d\n", view.Total, view.Letter, view.GradingBasis, view.Grade, view.SlipDaysUsed)
+	for _, comment := range view.Comments {
+		fmt.Fprintf(writer, "  %s\n", comment)
+	}
+
+	table := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "\nCategory\tWeight\tDrops\tRaw\tAdjusted\tWeighted\tComments")
+	for _, category := range view.Categories {
+		fmt.Fprintf(table, "%s\t%s%%\t%d\t%s%%\t%s%%\t%s%%\t%s\n", category.Name, category.Weight, category.Drops, category.Raw, category.Adjusted, category.Weighted, strings.Join(category.Comments, "; "))
+	}
+	fmt.Fprintln(table, "\nAssignment\tCategory\tScore\tRaw\tAdjusted\tSlip days\tComments")
+	for _, assignment := range view.Assignments {
+		comments := make([]string, 0)
+		if assignment.Dropped {
+			comments = append(comments, "Dropped")
+		}
+		for _, multiplier := range assignment.Multipliers {
+			comments = append(comments, fmt.Sprintf("x%v (%s)", multiplier.Factor, multiplier.Description))
+		}
+		comments = append(comments, assignment.Comments...)
+		fmt.Fprintf(table, "%s\t%s\t%s/%s\t%s%%\t%s%%\t%d\t%s\n", assignment.Name, assignment.Category, assignment.Score, assignment.MaxScore, assignment.Raw, assignment.Adjusted, assignment.SlipDays, strings.Join(comments, "; "))
+	}
+	table.Flush()
+}
+
+// printWhatIf prints the difference between a student's report before and
+// after the what-if changes.
+func printWhatIf(writer io.Writer, diff *grades.ReportDiff, rounding int) {
+	fmt.Fprintf(writer, "\nWhat if:\n  Total: %s%% (%s) -> %s%% (%s)\n",
+		grades.FormatPercent(diff.OldTotal, rounding), diff.OldLetter,
+		grades.FormatPercent(diff.NewTotal, rounding), diff.NewLetter)
+	if diff.GradeChanged() {
+		fmt.Fprintf(writer, "  Grade: %s -> %s\n", diff.OldGrade, diff.NewGrade)
+	}
+	for _, change := range diff.Categories {
+		fmt.Fprintf(writer, "  %s%s %s: %s%% -> %s%%\n", grades.CategoryColumnPrefix, change.Name, change.Field, grades.FormatPercent(change.Old, rounding), grades.FormatPercent(change.New, rounding))
+	}
+	for _, change := range diff.Assignments {
+		fmt.Fprintf(writer, "  %s %s: %s%% -> %s%%\n", change.Name, change.Field, grades.FormatPercent(change.Old, rounding), grades.FormatPercent(change.New, rounding))
+	}
+	for _, comment := range diff.RemovedComments {
+		fmt.Fprintf(writer, "  - %s\n", comment)
+	}
+	for _, comment := range diff.AddedComments {
+		fmt.Fprintf(writer, "  + %s\n", comment)
+	}
+}
+
+// lookupMain prints one student's finalized grade report, optionally with
+// what-if changes applied to that student only.
+func lookupMain(args []string) {
+	flags := flag.NewFlagSet("fromgradescope lookup", flag.ExitOnError)
+	in := &inputs{}
+	in.register(flags)
+
+	var rounding int
+	var extensionValues, drops, slipDays, overrides whatIfFlag
+	flags.IntVar(&rounding, "round", 2, "Number of decimal places to round percentages to")
+	flags.Var(&extensionValues, "what-if-extension", "What if the student had an extension, as assignment=extension, where an extension is days, a duration like 36h, a percentage like 50% or a new deadline in RFC 3339 or Gradescope's time format (repeatable)")
+	flags.Var(&drops, "what-if-drops", "What if the student had more drops, as category=count (repeatable)")
+	flags.Var(&slipDays, "what-if-slip-days", "What if the student had more slip days, as category=count (repeatable)")
+	flags.Var(&overrides, "what-if-score", "What if the student's score were overridden, as assignment=score (repeatable)")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s lookup [flags] <SID, email or name>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+
+	flags.Parse(args)
+
+	if !in.valid() || flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	result := compute(in)
+	sid, err := lookupStudent(flags.Arg(0), result.rosterEntries, strings.Split(in.matchRuleNames, ","))
+	panicIfErr(err)
+	before, ok := result.reports[sid]
+	if !ok {
+		panic(errors.New("No grade report for SID " + strconv.Itoa(sid)))
+	}
+
+	stages, err := whatIfStages(sid, result.course, extensionValues, drops, slipDays, overrides)
+	panicIfErr(err)
+	report := before
+	if len(stages) > 0 {
+		// Apply the pipeline to the whole roster, since some policies depend
+		// on every student, but only the student's report is changed.
+		pipeline, err := result.course.Pipeline.Insert(stages...)
+		panicIfErr(err)
+		reports, err := finalize(result.course, pipeline, result.roster, nil)
+		panicIfErr(err)
+		report = reports[sid]
+	}
+
+	categoryNames, assignmentNames := exportColumns(result.course.Categories, result.course.Assignments)
+	printReport(os.Stdout, newReportView(report, categoryNames, assignmentNames, rounding))
+	if len(stages) > 0 {
+		diffs := grades.DiffReports(map[int]*grades.GradeReport{sid: before}, map[int]*grades.GradeReport{sid: report}, 1e-9)
+		if len(diffs) == 0 {
+			fmt.Println("\nWhat if: no change")
+		} else {
+			printWhatIf(os.Stdout, diffs[0], rounding)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cs161-staff/grades"
+)
+
+func TestLookupStudent(t *testing.T) {
+	entries := []*rosterEntry{
+		{Student: &grades.Student{SID: 3031000001, Name: "Smith, Alice", Email: "alice@berkeley.edu"}},
+		{Student: &grades.Student{SID: 3031000002, Name: "Jones, Bob", Email: "bob@berkeley.edu"}},
+		{Student: &grades.Student{SID: 3031000012, Name: "Jones, Bob", Email: "bobby@berkeley.edu"}},
+	}
+	rules := []string{"sid", "email", "name"}
+	for query, expected := range map[string]int{
+		"3031000001":         3031000001,
+		"bobby@berkeley.edu": 3031000012,
+		"Alice Smith":        3031000001,
+	} {
+		sid, err := lookupStudent(query, entries, rules)
+		if err != nil || sid != expected {
+			t.Errorf("lookupStudent(%q) = %d, %v; expected %d", query, sid, err, expected)
+		}
+	}
+	for _, query := range []string{"Bob Jones", "3031000099", "eve@berkeley.edu"} {
+		if sid, err := lookupStudent(query, entries, rules); err == nil {
+			t.Errorf("lookupStudent(%q) = %d; expected an error", query, sid)
+		}
+	}
+}
+
+func TestWhatIfStages(t *testing.T) {
+	course := &grades.CourseConfig{
+		Categories: map[string]*grades.Category{"Homework": {Name: "Homework"}},
+		Assignments: map[string]*grades.Assignment{
+			"HW 1": {Name: "HW 1", CategoryName: "Homework", MaxScore: 10},
+		},
+		Pipeline: grades.Pipeline{{Name: "slipdays"}, {Name: "latemultipliers", Params: json.RawMessage(`{"scale": [0.9]}`)}, {Name: "drops"}},
+	}
+	stages, err := whatIfStages(1, course, whatIfFlag{"HW 1=36h"}, whatIfFlag{"Homework=1"}, nil, whatIfFlag{"HW 1=9"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pipeline, err := course.Pipeline.Insert(stages...)
+	if err != nil {
+		t.Fatalf("What-if pipeline is invalid: %v", err)
+	}
+	names := make([]string, len(pipeline))
+	for i, stage := range pipeline {
+		names[i] = stage.Name
+	}
+	expected := []string{"extensions", "changedrops", "slipdays", "latemultipliers", "overrides", "drops"}
+	if len(names) != len(expected) {
+		t.Fatalf("Got pipeline %v; expected %v", names, expected)
+	}
+	for i := range names {
+		if names[i] != expected[i] {
+			t.Fatalf("Got pipeline %v; expected %v", names, expected)
+		}
+	}
+	if params := string(pipeline[4].Params); params != `{"1":{"HW 1":9}}` {
+		t.Errorf("Got overrides parameters %s", params)
+	}
+
+	invalid := []struct {
+		drops     whatIfFlag
+		overrides whatIfFlag
+	}{
+		{drops: whatIfFlag{"Projects=1"}},
+		{drops: whatIfFlag{"Homework=1.5"}},
+		{drops: whatIfFlag{"Homework=1", "Homework=2"}},
+		{overrides: whatIfFlag{"HW 2=9"}},
+		{overrides: whatIfFlag{"HW 1=nine"}},
+	}
+	for _, c := range invalid {
+		if _, err := whatIfStages(1, course, nil, c.drops, nil, c.overrides); err == nil {
+			t.Errorf("What-if drops %v and overrides %v accepted", c.drops, c.overrides)
+		}
+	}
+}
+
+func TestWhatIfOverridesCSV(t *testing.T) {
+	course := &grades.CourseConfig{
+		Categories: map[string]*grades.Category{"Homework": {Name: "Homework", Weight: 1}},
+		Assignments: map[string]*grades.Assignment{
+			"HW 1": {Name: "HW 1", CategoryName: "Homework", MaxScore: 10, Weight: 1},
+		},
+		GradeBins: grades.DefaultGradeBins,
+		Pipeline:  grades.Pipeline{{Name: "slipdays"}, {Name: "latemultipliers", Params: json.RawMessage(`{"scale": []}`)}, {Name: "drops"}},
+	}
+	submissions := map[string]grades.AssignmentSubmission{"HW 1": {Score: 5}}
+	roster := grades.Roster{1: {grades.NewStudent(1, "Alice", course.Categories, course.Assignments, submissions)}}
+	applyOverrides(course, roster, map[int]map[string]float64{1: {"HW 1": 9}})
+
+	stages, err := whatIfStages(1, course, nil, nil, nil, whatIfFlag{"HW 1=7"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pipeline, err := course.Pipeline.Insert(stages...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reports, err := finalize(course, pipeline, roster, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The what-if override replaces the score from the overrides CSV.
+	if raw := reports[1].Assignments["HW 1"].Raw; raw != 0.7 {
+		t.Errorf("Got raw score %v; expected the what-if score 0.7", raw)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cs161-staff/grades"
+	"github.com/cs161-staff/grades/policies/clobber"
+)
+
+// importOverrides imports the CSV of score overrides at the given path, with
+// SID, Assignment and Score columns and optional Email and Name columns, and
+// returns each student's overridden scores by SID and assignment name. Rows
+// are matched to students with the resolver.
+func importOverrides(path string, assignments map[string]*grades.Assignment, resolver *identityResolver) map[int]map[string]float64 {
+	reader, err := NewDictReaderFromPath(path)
+	panicIfErr(err)
+
+	imported := make(map[int]map[string]float64)
+	for row, err := reader.Read(); err != io.EOF; row, err = reader.Read() {
+		panicIfErr(err)
+
+		name := strings.TrimSpace(row["Assignment"])
+		if _, ok := assignments[name]; !ok {
+			panic(fmt.Errorf("Unknown assignment in overrides: %s", name))
+		}
+		score, err := strconv.ParseFloat(strings.TrimSpace(row["Score"]), 64)
+		if err != nil {
+			panic(fmt.Errorf("Invalid override score for assignment %s: %w", name, err))
+		}
+		sid := resolver.ResolveRow("Overrides", row)
+		if sid == 0 {
+			continue
+		}
+		if _, ok := imported[sid]; !ok {
+			imported[sid] = make(map[string]float64)
+		}
+		if _, ok := imported[sid][name]; ok {
+			panic(fmt.Errorf("Duplicate override for SID %d on assignment %s", sid, name))
+		}
+		imported[sid][name] = score
+	}
+	return imported
+}
+
+// applyOverrides inserts a stage into the course's pipeline that applies the
+// overrides with the overrides policy, after the late multipliers. Students
+// who are not on the roster are reported and skipped.
+func applyOverrides(course *grades.CourseConfig, roster grades.Roster, imported map[int]map[string]float64) {
+	sids := make([]int, 0, len(imported))
+	for sid := range imported {
+		sids = append(sids, sid)
+	}
+	sort.Ints(sids)
+	params := make(map[int]map[string]float64, len(imported))
+	for _, sid := range sids {
+		if _, ok := roster[sid]; !ok {
+			warn("SID %d in overrides is not on the roster", sid)
+			continue
+		}
+		params[sid] = imported[sid]
+	}
+	if len(params) == 0 {
+		return
+	}
+	encoded, err := json.Marshal(params)
+	panicIfErr(err)
+	course.Pipeline, err = course.Pipeline.Insert(grades.Stage{Name: "overrides", Params: encoded})
+	panicIfErr(err)
+}
+
+// importClobbers imports the CSV of clobbers at the given path, with Source
+// and Target columns naming assignments and an optional Style column that is
+// either "scaled" (the default) or "zscore".
+func importClobbers(path string, assignments map[string]*grades.Assignment) []clobber.Params {
+	reader, err := NewDictReaderFromPath(path)
+	panicIfErr(err)
+
+	imported := make([]clobber.Params, 0)
+	for row, err := reader.Read(); err != io.EOF; row, err = reader.Read() {
+		panicIfErr(err)
+
+		params := clobber.Params{
+			Source: strings.TrimSpace(row["Source"]),
+			Target: strings.TrimSpace(row["Target"]),
+			Style:  strings.ToLower(strings.TrimSpace(row["Style"])),
+		}
+		for _, name := range []string{params.Source, params.Target} {
+			if _, ok := assignments[name]; !ok {
+				panic(fmt.Errorf("Unknown assignment in clobbers: %s", name))
+			}
+		}
+		if params.Source == params.Target {
+			panic(fmt.Errorf("Clobber from assignment %s to itself", params.Source))
+		}
+		if params.Style != "" && params.Style != "scaled" && params.Style != "zscore" {
+			panic(fmt.Errorf("Invalid clobber style %s from %s to %s", params.Style, params.Source, params.Target))
+		}
+		imported = append(imported, params)
+	}
+	return imported
+}
+
+// applyClobbers inserts a clobber stage into the course's pipeline for each
+// clobber, in order, after the overrides and late multipliers.
+func applyClobbers(course *grades.CourseConfig, imported []clobber.Params) {
+	stages := make([]grades.Stage, len(imported))
+	for i, params := range imported {
+		encoded, err := json.Marshal(params)
+		panicIfErr(err)
+		stages[i] = grades.Stage{Name: "clobber", Params: encoded}
+	}
+	pipeline, err := course.Pipeline.Insert(stages...)
+	panicIfErr(err)
+	course.Pipeline = pipeline
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/cs161-staff/grades"
+)
+
+func TestOverrides(t *testing.T) {
+	assignments := map[string]*grades.Assignment{
+		"HW 1": {Name: "HW 1", CategoryName: "Homework", MaxScore: 10},
+	}
+	entries := []*rosterEntry{
+		{Student: &grades.Student{SID: 3031000001, Name: "Smith, Alice", Email: "alice@berkeley.edu"}},
+	}
+	resolver, err := newIdentityResolver(entries, []string{"sid", "email"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := writeTemp(t, "overrides.csv", "SID,Email,Assignment,Score\n,alice@berkeley.edu,HW 1,9.5\n3031000099,,HW 1,1\n")
+	imported := importOverrides(path, assignments, resolver)
+	if len(imported) != 1 || imported[3031000001]["HW 1"] != 9.5 {
+		t.Errorf("Got overrides %v", imported)
+	}
+
+	course := &grades.CourseConfig{
+		Pipeline: grades.Pipeline{{Name: "slipdays"}, {Name: "latemultipliers", Params: []byte(`{"scale": []}`)}, {Name: "drops"}},
+	}
+	roster := grades.Roster{3031000001: {{SID: 3031000001}}}
+	applyOverrides(course, roster, imported)
+	if len(course.Pipeline) != 4 || course.Pipeline[2].Name != "overrides" || string(course.Pipeline[2].Params) != `{"3031000001":{"HW 1":9.5}}` {
+		t.Errorf("Got pipeline %+v", course.Pipeline)
+	}
+	if err := course.Pipeline.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	expectPanic(t, "Override on an unknown assignment", func() {
+		importOverrides(writeTemp(t, "overrides.csv", "SID,Assignment,Score\n3031000001,HW 2,1\n"), assignments, resolver)
+	})
+	expectPanic(t, "Override with an invalid score", func() {
+		importOverrides(writeTemp(t, "overrides.csv", "SID,Assignment,Score\n3031000001,HW 1,ten\n"), assignments, resolver)
+	})
+	expectPanic(t, "Duplicate override", func() {
+		importOverrides(writeTemp(t, "overrides.csv", "SID,Assignment,Score\n3031000001,HW 1,1\n3031000001,HW 1,2\n"), assignments, resolver)
+	})
+}
+
+func TestClobbers(t *testing.T) {
+	assignments := map[string]*grades.Assignment{
+		"Midterm": {Name: "Midterm", CategoryName: "Exams", MaxScore: 100},
+		"Final":   {Name: "Final", CategoryName: "Exams", MaxScore: 200},
+	}
+	path := writeTemp(t, "clobbers.csv", "Source,Target,Style\nFinal,Midterm,ZScore\n")
+	imported := importClobbers(path, assignments)
+	if len(imported) != 1 || imported[0].Source != "Final" || imported[0].Target != "Midterm" || imported[0].Style != "zscore" {
+		t.Errorf("Got clobbers %+v", imported)
+	}
+
+	course := &grades.CourseConfig{
+		Pipeline: grades.Pipeline{{Name: "overrides", Params: []byte(`{}`)}, {Name: "drops"}},
+	}
+	applyClobbers(course, imported)
+	if len(course.Pipeline) != 3 || course.Pipeline[1].Name != "clobber" {
+		t.Errorf("Got pipeline %+v", course.Pipeline)
+	}
+	if err := course.Pipeline.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	expectPanic(t, "Clobber from an unknown assignment", func() {
+		importClobbers(writeTemp(t, "clobbers.csv", "Source,Target\nQuiz,Midterm\n"), assignments)
+	})
+	expectPanic(t, "Clobber of an assignment with itself", func() {
+		importClobbers(writeTemp(t, "clobbers.csv", "Source,Target\nFinal,Final\n"), assignments)
+	})
+	expectPanic(t, "Clobber with an unknown style", func() {
+		importClobbers(writeTemp(t, "clobbers.csv", "Source,Target,Style\nFinal,Midterm,best\n"), assignments)
+	})
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/cs161-staff/grades"
+)
+
+// exportRegistrar writes the CalCentral grade upload file to the given path.
+// Every enrolled student on the roster must have exactly one grade; if any do
+// not, nothing is written and the problems are returned as an error. Grading
+// bases are written as their CalCentral codes.
+func exportRegistrar(path string, entries []*rosterEntry, reports map[int]*grades.GradeReport) error {
+	rows := [][]string{{"SID", "Name", "Grade", "Grading Basis"}}
+	problems := make([]string, 0)
+	seen := make(map[int]bool, len(entries))
+	for _, entry := range entries {
+		if entry.Status != statusEnrolled {
+			continue
+		}
+		sid := entry.Student.SID
+		if seen[sid] {
+			problems = append(problems, fmt.Sprintf("SID %d appears more than once on the roster", sid))
+			continue
+		}
+		seen[sid] = true
+		report, ok := reports[sid]
+		if !ok || report.Grade == "" {
+			problems = append(problems, fmt.Sprintf("%s (SID %d) has no grade", entry.Student.Name, sid))
+			continue
+		}
+		rows = append(rows, []string{strconv.Itoa(sid), entry.Student.Name, report.Grade, entry.Student.GradingBasis.String()})
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("Cannot write registrar upload:\n  %s", strings.Join(problems, "\n  "))
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	writer := csv.NewWriter(file)
+	writer.WriteAll(rows)
+	return writer.Error()
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cs161-staff/grades"
+)
+
+func TestExportRegistrar(t *testing.T) {
+	course := &grades.CourseConfig{GradeBins: grades.DefaultGradeBins, PassingLetter: grades.DefaultPassingLetter}
+	entries := []*rosterEntry{
+		{Student: &grades.Student{SID: 3031000001, Name: "Smith, Alice", GradingBasis: grades.BasisLetter}},
+		{Student: &grades.Student{SID: 3031000002, Name: "Jones, Bob", GradingBasis: grades.BasisPassNoPass}},
+		{Student: &grades.Student{SID: 3031000003, Name: "Lee, Carol", GradingBasis: grades.BasisPassNoPass}},
+		{Student: &grades.Student{SID: 3031000004, Name: "Wu, Daniel", GradingBasis: grades.BasisLetter}},
+		{Student: &grades.Student{SID: 3031000005, Name: "Park, Eve"}, Status: statusWaitlisted},
+	}
+	totals := []float64{0.95, 0.8, 0.5, 0.9}
+	reports := make(map[int]*grades.GradeReport)
+	for i, total := range totals {
+		report := &grades.GradeReport{Student: entries[i].Student, TotalScore: total}
+		if i == 3 {
+			report.Pending = []string{"Final"}
+		}
+		if err := course.AssignGrade(report); err != nil {
+			t.Fatal(err)
+		}
+		reports[report.Student.SID] = report
+	}
+
+	path := filepath.Join(t.TempDir(), "registrar.csv")
+	if err := exportRegistrar(path, entries, reports); err != nil {
+		t.Fatal(err)
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// P/NP students get P or NP, students with pending assignments get an
+	// Incomplete, and students who are not enrolled are left out.
+	expected := `SID,Name,Grade,Grading Basis
+3031000001,"Smith, Alice",A,GRD
+3031000002,"Jones, Bob",P,PNP
+3031000003,"Lee, Carol",NP,PNP
+3031000004,"Wu, Daniel",I,GRD
+`
+	if string(contents) != expected {
+		t.Errorf("Got registrar upload %q; expected %q", contents, expected)
+	}
+
+	// Nothing is written if an enrolled student has no grade.
+	delete(reports, 3031000002)
+	path = filepath.Join(t.TempDir(), "registrar.csv")
+	if err := exportRegistrar(path, entries, reports); err == nil || !strings.Contains(err.Error(), "Jones, Bob (SID 3031000002) has no grade") {
+		t.Errorf("Got error %v for a student without a grade", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Registrar upload written with a missing grade")
+	}
+}
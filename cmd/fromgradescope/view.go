@@ -0,0 +1,101 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/cs161-staff/grades"
+)
+
+// reportView is the data used to render a student's grade report in HTML or
+// email templates, with scores formatted as percentages.
+type reportView struct {
+	Name         string
+	SID          int
+	Email        string
+	Total        string
+	Letter       string
+	GradingBasis string
+	Grade        string
+	SlipDaysUsed int
+	Comments     []string
+	Categories   []categoryView
+	Assignments  []assignmentView
+}
+
+// categoryView is a category in a reportView.
+type categoryView struct {
+	Name     string
+	Weight   string
+	Drops    int
+	Raw      string
+	Adjusted string
+	Weighted string
+	Comments []string
+}
+
+// assignmentView is an assignment in a reportView.
+type assignmentView struct {
+	Name        string
+	Category    string
+	Score       string
+	MaxScore    string
+	Raw         string
+	Multipliers []grades.Multiplier
+	Adjusted    string
+	Weighted    string
+	Dropped     bool
+	SlipDays    int
+	Comments    []string
+}
+
+// newReportView returns the view of the given finalized report, listing
+// categories and assignments in export column order. Multipliers are listed
+// separately from the assignment comments, so the submission's comments are
+// used rather than the report's.
+func newReportView(report *grades.GradeReport, categoryNames []string, assignmentNames []string, rounding int) *reportView {
+	student := report.Student
+	data := &reportView{
+		Name:         student.Name,
+		SID:          student.SID,
+		Email:        student.Email,
+		Total:        grades.FormatPercent(report.TotalScore, rounding),
+		Letter:       report.Letter,
+		GradingBasis: student.GradingBasis.String(),
+		Grade:        report.Grade,
+		SlipDaysUsed: student.SlipDaysUsed,
+		Comments:     report.Comments,
+		Categories:   make([]categoryView, 0, len(categoryNames)),
+		Assignments:  make([]assignmentView, 0, len(assignmentNames)),
+	}
+	for _, name := range categoryNames {
+		category := student.Categories[name]
+		categoryReport := report.Categories[name]
+		data.Categories = append(data.Categories, categoryView{
+			Name:     name,
+			Weight:   grades.FormatPercent(category.Weight, rounding),
+			Drops:    category.Drops,
+			Raw:      grades.FormatPercent(categoryReport.Raw, rounding),
+			Adjusted: grades.FormatPercent(categoryReport.Adjusted, rounding),
+			Weighted: grades.FormatPercent(categoryReport.Weighted, rounding),
+			Comments: categoryReport.Comments,
+		})
+	}
+	for _, name := range assignmentNames {
+		assignment := student.Assignments[name]
+		assignmentReport := report.Assignments[name]
+		data.Assignments = append(data.Assignments, assignmentView{
+			Name:        name,
+			Category:    assignment.CategoryName,
+			Score:       strconv.FormatFloat(assignment.Grade.Score, 'f', -1, 64),
+			MaxScore:    strconv.FormatFloat(assignment.MaxScore, 'f', -1, 64),
+			Raw:         grades.FormatPercent(assignmentReport.Raw, rounding),
+			Multipliers: assignment.Grade.MultipliersApplied,
+			Adjusted:    grades.FormatPercent(assignmentReport.Adjusted, rounding),
+			Weighted:    grades.FormatPercent(assignmentReport.Weighted, rounding),
+			Dropped:     assignment.Grade.Dropped,
+			SlipDays:    assignment.Grade.SlipDaysApplied,
+			Comments:    assignment.Grade.Comments,
+		})
+	}
+	return data
+}
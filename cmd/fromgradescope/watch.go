@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/cs161-staff/grades"
+)
+
+// watchRounding is the number of decimal places of the totals printed when
+// watching the inputs, so that small changes are visible.
+const watchRounding = 2
+
+// paths returns the paths of the input files that are set.
+func (in *inputs) paths() []string {
+	paths := make([]string, 0)
+	for _, path := range []string{
+		in.rosterPath, in.gradesPath, in.configPath, in.categoriesPath, in.assignmentsPath,
+		in.overridesPath, in.clobbersPath, in.extensionsPath, in.accommodationsPath,
+		in.incompletesPath, in.identitiesPath, in.binsPath, in.canvasPath,
+	} {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// modTimes returns the modification time of each file. Files that cannot be
+// read, such as those being replaced, have the zero time.
+func modTimes(paths []string) map[string]time.Time {
+	times := make(map[string]time.Time, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err == nil {
+			times[path] = info.ModTime()
+		} else {
+			times[path] = time.Time{}
+		}
+	}
+	return times
+}
+
+// changedPaths returns the paths whose modification times differ, sorted.
+func changedPaths(before map[string]time.Time, after map[string]time.Time) []string {
+	changed := make([]string, 0)
+	for path, modTime := range after {
+		if !modTime.Equal(before[path]) {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// tryRun calls run, returning a panic as an error so that a mistake in an input
+// file being edited does not stop the watch.
+func tryRun(run func() *computation) (result *computation, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				err = e
+			} else {
+				err = fmt.Errorf("%v", r)
+			}
+		}
+	}()
+	return run(), nil
+}
+
+// printChanges prints the students whose totals or letter grades changed
+// between the reports before and after, largest changes first.
+func printChanges(writer io.Writer, before map[int]*grades.GradeReport, after map[int]*grades.GradeReport) {
+	changed := 0
+	for _, diff := range grades.DiffReports(before, after, 1e-9) {
+		switch {
+		case diff.Added:
+			fmt.Fprintf(writer, "  %d %s: added with %s%% (%s)\n", diff.SID, diff.Name, grades.FormatPercent(diff.NewTotal, watchRounding), diff.NewLetter)
+		case diff.Removed:
+			fmt.Fprintf(writer, "  %d %s: removed\n", diff.SID, diff.Name)
+		case diff.LetterChanged() || grades.FormatPercent(diff.OldTotal, watchRounding) != grades.FormatPercent(diff.NewTotal, watchRounding):
+			fmt.Fprintf(writer, "  %d %s: %s%% (%s) -> %s%% (%s)\n", diff.SID, diff.Name,
+				grades.FormatPercent(diff.OldTotal, watchRounding), diff.OldLetter,
+				grades.FormatPercent(diff.NewTotal, watchRounding), diff.NewLetter)
+		default:
+			continue
+		}
+		changed++
+	}
+	fmt.Fprintf(writer, "%d of %d students changed\n", changed, len(after))
+}
+
+// watch calls run, then polls the input files every interval and calls run
+// again whenever one of them changes, printing which students' totals or
+// letter grades changed since the last successful run. Errors are printed and
+// the previous results kept until the inputs change again. It never returns.
+func watch(in *inputs, interval time.Duration, run func() *computation) {
+	paths := in.paths()
+	result := run()
+	// Take the modification times after each run, since the identities file
+	// is rewritten by the run itself.
+	times := modTimes(paths)
+	fmt.Fprintf(os.Stderr, "Watching %d input files for changes\n", len(paths))
+	for {
+		time.Sleep(interval)
+		changed := changedPaths(times, modTimes(paths))
+		if len(changed) == 0 {
+			continue
+		}
+		// Wait for the files to settle, since editors and downloads may write
+		// them in several steps.
+		for settled := modTimes(paths); ; settled = modTimes(paths) {
+			time.Sleep(interval)
+			if len(changedPaths(settled, modTimes(paths))) == 0 {
+				break
+			}
+		}
+
+		fmt.Fprintf(os.Stderr, "\n%s: changed %v\n", time.Now().Format("15:04:05"), changed)
+		next, err := tryRun(run)
+		times = modTimes(paths)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			continue
+		}
+		printChanges(os.Stderr, result.reports, next.reports)
+		result = next
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cs161-staff/grades"
+)
+
+func TestChangedPaths(t *testing.T) {
+	now := time.Now()
+	before := map[string]time.Time{"a.csv": now, "b.csv": now, "c.csv": now}
+	after := map[string]time.Time{"a.csv": now, "b.csv": now.Add(time.Second), "c.csv": {}, "d.csv": now}
+	if changed := changedPaths(before, after); !reflect.DeepEqual(changed, []string{"b.csv", "c.csv", "d.csv"}) {
+		t.Errorf("Got changed paths %v", changed)
+	}
+	if changed := changedPaths(before, before); len(changed) != 0 {
+		t.Errorf("Got changed paths %v for unchanged files", changed)
+	}
+}
+
+func TestPrintChanges(t *testing.T) {
+	report := func(sid int, name string, total float64, letter string) *grades.GradeReport {
+		return &grades.GradeReport{
+			Student:    &grades.Student{SID: sid, Name: name},
+			TotalScore: total,
+			Letter:     letter,
+		}
+	}
+	before := map[int]*grades.GradeReport{
+		1: report(1, "Alice", 0.9, "A-"),
+		2: report(2, "Bob", 0.8, "B-"),
+		3: report(3, "Carol", 0.7, "C-"),
+		4: report(4, "Dave", 0.6, "D-"),
+	}
+	after := map[int]*grades.GradeReport{
+		1: report(1, "Alice", 0.9, "A-"),
+		// A change too small to see at the watch rounding is not printed.
+		2: report(2, "Bob", 0.8000001, "B-"),
+		3: report(3, "Carol", 0.75, "C"),
+		5: report(5, "Eve", 0.5, "F"),
+	}
+	var output bytes.Buffer
+	printChanges(&output, before, after)
+	expected := "  4 Dave: removed\n" +
+		"  5 Eve: added with 50.00% (F)\n" +
+		"  3 Carol: 70.00% (C-) -> 75.00% (C)\n" +
+		"3 of 4 students changed\n"
+	if output.String() != expected {
+		t.Errorf("Got output:\n%s\nexpected:\n%s", output.String(), expected)
+	}
+}
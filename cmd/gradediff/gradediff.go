@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/cs161-staff/grades"
+)
+
+func panicIfErr(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// loadReports loads grade reports from a JSON file written by fromgradescope
+// -json or a grades CSV written by fromgradescope, chosen by the file
+// extension.
+func loadReports(path string) (map[int]*grades.GradeReport, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		return grades.ReadReportsJSON(file)
+	case ".csv":
+		return readReportsCSV(file)
+	default:
+		return nil, fmt.Errorf("Unknown grade report format %q", ext)
+	}
+}
+
+// readReportsCSV reads grade reports from a grades CSV written by
+// fromgradescope with grades.WriteReportsCSV. Scores in the CSV are
+// percentages, and comments are not attributed to their categories and
+// assignments.
+func readReportsCSV(reader io.Reader) (map[int]*grades.GradeReport, error) {
+	csvReader := csv.NewReader(reader)
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		columns[column] = i
+	}
+	for _, column := range []string{"SID", "Name", "Total", "Letter", "Comments"} {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("Grades CSV is missing column %s", column)
+		}
+	}
+
+	reports := make(map[int]*grades.GradeReport)
+	for row, err := csvReader.Read(); err != io.EOF; row, err = csvReader.Read() {
+		if err != nil {
+			return nil, err
+		}
+		sid, err := strconv.Atoi(row[columns["SID"]])
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := reports[sid]; ok {
+			return nil, fmt.Errorf("Duplicate grade report for SID %d", sid)
+		}
+		total, err := parsePercent(row[columns["Total"]])
+		if err != nil {
+			return nil, err
+		}
+		report := &grades.GradeReport{
+			Student: &grades.Student{
+				SID:  sid,
+				Name: row[columns["Name"]],
+			},
+			TotalScore:  total,
+			Letter:      row[columns["Letter"]],
+			Categories:  make(map[string]*grades.ReportCategory),
+			Assignments: make(map[string]*grades.ReportAssignment),
+			Comments:    make([]string, 0),
+		}
+		if column, ok := columns["Grade"]; ok {
+			report.Grade = row[column]
+		}
+		if comments := row[columns["Comments"]]; comments != "" {
+			report.Comments = strings.Split(comments, "; ")
+		}
+
+		for i, column := range header {
+			var name string
+			var field string
+			for _, suffix := range []string{" Raw", " Adjusted", " Weighted"} {
+				if strings.HasSuffix(column, suffix) {
+					name = strings.TrimSuffix(column, suffix)
+					field = suffix[1:]
+				}
+			}
+			if field == "" {
+				continue
+			}
+			score, err := parsePercent(row[i])
+			if err != nil {
+				return nil, err
+			}
+
+			var raw, adjusted, weighted *float64
+			if strings.HasPrefix(name, grades.CategoryColumnPrefix) {
+				name = strings.TrimPrefix(name, grades.CategoryColumnPrefix)
+				category, ok := report.Categories[name]
+				if !ok {
+					category = &grades.ReportCategory{}
+					report.Categories[name] = category
+				}
+				raw, adjusted, weighted = &category.Raw, &category.Adjusted, &category.Weighted
+			} else {
+				assignment, ok := report.Assignments[name]
+				if !ok {
+					assignment = &grades.ReportAssignment{}
+					report.Assignments[name] = assignment
+				}
+				raw, adjusted, weighted = &assignment.Raw, &assignment.Adjusted, &assignment.Weighted
+			}
+			switch field {
+			case "Raw":
+				*raw = score
+			case "Adjusted":
+				*adjusted = score
+			case "Weighted":
+				*weighted = score
+			}
+		}
+		reports[sid] = report
+	}
+	return reports, nil
+}
+
+// parsePercent parses a percentage into a score from 0 to 1.
+func parsePercent(value string) (float64, error) {
+	percent, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	return percent / 100.0, nil
+}
+
+// printDiff prints a human-readable summary of a student's changes.
+func printDiff(writer io.Writer, diff *grades.ReportDiff, rounding int) {
+	status := ""
+	if diff.Added {
+		status = " (added)"
+	} else if diff.Removed {
+		status = " (removed)"
+	}
+	fmt.Fprintf(writer, "%d %s%s\n", diff.SID, diff.Name, status)
+	fmt.Fprintf(writer, "  Total: %s%% -> %s%% (%+.*f)\n",
+		grades.FormatPercent(diff.OldTotal, rounding),
+		grades.FormatPercent(diff.NewTotal, rounding),
+		rounding, diff.TotalChange()*100.0)
+	if diff.LetterChanged() {
+		fmt.Fprintf(writer, "  Letter: %s -> %s\n", diff.OldLetter, diff.NewLetter)
+	}
+	if diff.GradeChanged() {
+		fmt.Fprintf(writer, "  Grade: %s -> %s\n", diff.OldGrade, diff.NewGrade)
+	}
+	for _, change := range diff.Categories {
+		fmt.Fprintf(writer, "  %s%s %s: %s%% -> %s%%\n", grades.CategoryColumnPrefix, change.Name, change.Field, grades.FormatPercent(change.Old, rounding), grades.FormatPercent(change.New, rounding))
+	}
+	for _, change := range diff.Assignments {
+		fmt.Fprintf(writer, "  %s %s: %s%% -> %s%%\n", change.Name, change.Field, grades.FormatPercent(change.Old, rounding), grades.FormatPercent(change.New, rounding))
+	}
+	for _, comment := range diff.RemovedComments {
+		fmt.Fprintf(writer, "  - %s\n", comment)
+	}
+	for _, comment := range diff.AddedComments {
+		fmt.Fprintf(writer, "  + %s\n", comment)
+	}
+}
+
+func main() {
+	var rounding int
+	var tolerance float64
+	flag.IntVar(&rounding, "round", 2, "Number of decimal places to round percentages to")
+	flag.Float64Var(&tolerance, "tolerance", 1e-9, "Largest difference in a score, from 0 to 1, that is not considered a change")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] old new\n\nCompares two sets of grade reports written by fromgradescope as JSON (-json)\nor CSV and lists the students whose grades changed, largest changes first.\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	if tolerance < 0 {
+		panic(errors.New("-tolerance must not be negative"))
+	}
+
+	before, err := loadReports(flag.Arg(0))
+	panicIfErr(err)
+	after, err := loadReports(flag.Arg(1))
+	panicIfErr(err)
+
+	diffs := grades.DiffReports(before, after, tolerance)
+	for i, diff := range diffs {
+		if i > 0 {
+			fmt.Println()
+		}
+		printDiff(os.Stdout, diff, rounding)
+	}
+	fmt.Fprintf(os.Stderr, "%d of %d students changed\n", len(diffs), len(unionSIDs(before, after)))
+}
+
+// unionSIDs returns the set of SIDs with a report in either set of reports.
+func unionSIDs(before map[int]*grades.GradeReport, after map[int]*grades.GradeReport) map[int]bool {
+	sids := make(map[int]bool, len(before))
+	for sid := range before {
+		sids[sid] = true
+	}
+	for sid := range after {
+		sids[sid] = true
+	}
+	return sids
+}
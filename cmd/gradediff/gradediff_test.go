@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cs161-staff/grades"
+)
+
+func TestReadReportsCSV(t *testing.T) {
+	reports, err := readReportsCSV(strings.NewReader(`SID,Name,HW 1 Raw,HW 1 Adjusted,HW 1 Weighted,Category: Homework Raw,Category: Homework Adjusted,Category: Homework Weighted,Total,Letter,Grade,Comments
+3031000001,"Smith, Alice",90,81,40.5,90,81,81,81,B-,P,HW 1: Late; Capped at B
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := reports[3031000001]
+	if report == nil || report.Student.Name != "Smith, Alice" || report.TotalScore != 0.81 || report.Letter != "B-" || report.Grade != "P" {
+		t.Fatalf("Got report %+v", report)
+	}
+	if assignment := report.Assignments["HW 1"]; assignment == nil || assignment.Raw != 0.9 || assignment.Adjusted != 0.81 || assignment.Weighted != 0.405 {
+		t.Errorf("Got HW 1 scores %+v", assignment)
+	}
+	if category := report.Categories["Homework"]; category == nil || category.Raw != 0.9 || category.Weighted != 0.81 {
+		t.Errorf("Got Homework scores %+v", category)
+	}
+	if len(report.Comments) != 2 || report.Comments[0] != "HW 1: Late" {
+		t.Errorf("Got comments %q", report.Comments)
+	}
+
+	for _, invalid := range []string{
+		"SID,Name,Total,Letter\n1,Alice,90,A-\n",
+		"SID,Name,Total,Letter,Comments\n1,Alice,90,A-,\n1,Alice,90,A-,\n",
+		"SID,Name,Total,Letter,Comments\n1,Alice,ninety,A-,\n",
+	} {
+		if _, err := readReportsCSV(strings.NewReader(invalid)); err == nil {
+			t.Errorf("Grades CSV %q accepted", invalid)
+		}
+	}
+}
+
+func TestReadReportsCSVRoundTrip(t *testing.T) {
+	categories := map[string]*grades.Category{
+		"Homework": {Name: "Homework", Weight: 0.4},
+		"Exams":    {Name: "Exams", Weight: 0.6},
+	}
+	assignments := map[string]*grades.Assignment{
+		"HW 1":  {Name: "HW 1", CategoryName: "Homework", MaxScore: 10, Weight: 1},
+		"Final": {Name: "Final", CategoryName: "Exams", MaxScore: 100, Weight: 1},
+	}
+	student := grades.NewStudent(3031000001, "Smith, Alice", categories, assignments, map[string]grades.AssignmentSubmission{
+		"HW 1":  {Score: 7, Comments: []string{"Regrade"}},
+		"Final": {Score: 88},
+	})
+	report := student.GenerateGradeReport()
+	report.Letter, report.Grade = "B+", "B+"
+	report.Comments = append(report.Comments, "Capped at B+")
+	before := map[int]*grades.GradeReport{3031000001: report}
+
+	var output bytes.Buffer
+	if err := grades.WriteReportsCSV(&output, before, []string{"Exams", "Homework"}, []string{"Final", "HW 1"}, 6); err != nil {
+		t.Fatal(err)
+	}
+	after, err := readReportsCSV(&output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diffs := grades.DiffReports(before, after, 1e-8); len(diffs) != 0 {
+		t.Errorf("Reports changed when written and read back: %+v", diffs[0])
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// principal is the identity that a request is authenticated as.
+type principal struct {
+	// Staff is whether the principal is course staff, who may see every
+	// student's grades.
+	Staff bool
+
+	// SID is the student ID of a student principal.
+	SID int
+}
+
+// authenticator authenticates requests by their bearer token. Authenticators
+// are tried in order until one recognizes the token.
+type authenticator interface {
+	// Authenticate returns the principal that the token belongs to and
+	// whether the token was recognized.
+	Authenticate(token string) (principal, bool)
+}
+
+// tokenHash is the hash of a token. Tokens are stored and looked up by their
+// hash so that lookups do not compare the secret tokens themselves.
+type tokenHash [sha256.Size]byte
+
+func hashToken(token string) tokenHash {
+	return sha256.Sum256([]byte(token))
+}
+
+// staffTokens authenticates staff by a static set of tokens.
+type staffTokens map[tokenHash]bool
+
+// loadStaffTokens loads staff tokens from the file at the given path, one per
+// line. Blank lines and lines beginning with # are ignored.
+func loadStaffTokens(path string) (staffTokens, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	tokens := make(staffTokens)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		token := strings.TrimSpace(scanner.Text())
+		if token == "" || strings.HasPrefix(token, "#") {
+			continue
+		}
+		tokens[hashToken(token)] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("No staff tokens in %s", path)
+	}
+	return tokens, nil
+}
+
+func (tokens staffTokens) Authenticate(token string) (principal, bool) {
+	if !tokens[hashToken(token)] {
+		return principal{}, false
+	}
+	return principal{Staff: true}, true
+}
+
+// studentTokens authenticates students by a token for each SID.
+type studentTokens map[tokenHash]int
+
+// loadStudentTokens loads student tokens from the CSV at the given path, with
+// SID and Token columns.
+func loadStudentTokens(path string) (studentTokens, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	sidColumn, tokenColumn := -1, -1
+	for i, column := range header {
+		switch column {
+		case "SID":
+			sidColumn = i
+		case "Token":
+			tokenColumn = i
+		}
+	}
+	if sidColumn < 0 || tokenColumn < 0 {
+		return nil, errors.New("Student tokens CSV must have SID and Token columns")
+	}
+
+	tokens := make(studentTokens)
+	for row, err := reader.Read(); err != io.EOF; row, err = reader.Read() {
+		if err != nil {
+			return nil, err
+		}
+		sid, err := strconv.Atoi(row[sidColumn])
+		if err != nil {
+			return nil, err
+		}
+		token := strings.TrimSpace(row[tokenColumn])
+		if token == "" {
+			return nil, fmt.Errorf("Empty token for SID %d", sid)
+		}
+		hash := hashToken(token)
+		if _, ok := tokens[hash]; ok {
+			return nil, fmt.Errorf("Token for SID %d is not unique", sid)
+		}
+		tokens[hash] = sid
+	}
+	return tokens, nil
+}
+
+func (tokens studentTokens) Authenticate(token string) (principal, bool) {
+	sid, ok := tokens[hashToken(token)]
+	if !ok {
+		return principal{}, false
+	}
+	return principal{SID: sid}, true
+}
+
+// authenticate returns the principal of the request's bearer token using the
+// first authenticator that recognizes it.
+func authenticate(authenticators []authenticator, request *http.Request) (principal, bool) {
+	const prefix = "Bearer "
+	header := request.Header.Get("Authorization")
+	if !strings.HasPrefix(header, prefix) {
+		return principal{}, false
+	}
+	token := strings.TrimSpace(strings.TrimPrefix(header, prefix))
+	if token == "" {
+		return principal{}, false
+	}
+	for _, auth := range authenticators {
+		if principal, ok := auth.Authenticate(token); ok {
+			return principal, true
+		}
+	}
+	return principal{}, false
+}
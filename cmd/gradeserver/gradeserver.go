@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cs161-staff/grades"
+)
+
+func panicIfErr(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// server serves grade reports computed ahead of time, along with statistics
+// derived from them when they are loaded.
+type server struct {
+	reports         map[int]*grades.GradeReport
+	assignmentStats map[string]*assignmentStats
+	distribution    *distribution
+	authenticators  []authenticator
+
+	// publicStats is whether students may see assignment statistics and the
+	// course distribution, which are otherwise only visible to staff.
+	publicStats bool
+}
+
+// newServer returns a server for the reports.
+func newServer(reports map[int]*grades.GradeReport, authenticators []authenticator, publicStats bool) *server {
+	return &server{
+		reports:         reports,
+		assignmentStats: newAssignmentStats(reports),
+		distribution:    newDistribution(reports),
+		authenticators:  authenticators,
+		publicStats:     publicStats,
+	}
+}
+
+// studentSummary is a student in the list of students.
+type studentSummary struct {
+	SID        int     `json:"sid"`
+	Name       string  `json:"name"`
+	TotalScore float64 `json:"total"`
+	Letter     string  `json:"letter,omitempty"`
+	Grade      string  `json:"grade,omitempty"`
+}
+
+// handler returns the HTTP handler for the API.
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/students", s.authenticated(s.handleStudents))
+	mux.HandleFunc("/students/", s.authenticated(s.handleStudent))
+	mux.HandleFunc("/assignments", s.authenticated(s.handleAssignments))
+	mux.HandleFunc("/assignments/", s.authenticated(s.handleAssignment))
+	mux.HandleFunc("/distribution", s.authenticated(s.handleDistribution))
+	return mux
+}
+
+// authenticated wraps a handler so that it is only called for GET requests
+// with a recognized token.
+func (s *server) authenticated(handler func(http.ResponseWriter, *http.Request, principal)) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet && request.Method != http.MethodHead {
+			writeError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		principal, ok := authenticate(s.authenticators, request)
+		if !ok {
+			writer.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(writer, http.StatusUnauthorized, "Missing or unrecognized token")
+			return
+		}
+		handler(writer, request, principal)
+	}
+}
+
+// handleStudents lists every student's total and letter grade for staff.
+func (s *server) handleStudents(writer http.ResponseWriter, request *http.Request, principal principal) {
+	if !principal.Staff {
+		writeError(writer, http.StatusForbidden, "Only staff may list students")
+		return
+	}
+	students := make([]studentSummary, 0, len(s.reports))
+	for _, sid := range sortedSIDs(s.reports) {
+		report := s.reports[sid]
+		students = append(students, studentSummary{
+			SID:        sid,
+			Name:       report.Student.Name,
+			TotalScore: report.TotalScore,
+			Letter:     report.Letter,
+			Grade:      report.Grade,
+		})
+	}
+	writeJSON(writer, students)
+}
+
+// handleStudent serves a student's grade report at /students/<SID>, or the
+// requesting student's own report at /students/me. Students may only see
+// their own report.
+func (s *server) handleStudent(writer http.ResponseWriter, request *http.Request, principal principal) {
+	key := strings.TrimPrefix(request.URL.Path, "/students/")
+	var sid int
+	if key == "me" {
+		if principal.Staff {
+			writeError(writer, http.StatusNotFound, "Staff do not have a grade report")
+			return
+		}
+		sid = principal.SID
+	} else {
+		var err error
+		sid, err = strconv.Atoi(key)
+		if err != nil {
+			writeError(writer, http.StatusNotFound, "Invalid SID")
+			return
+		}
+	}
+	if !principal.Staff && principal.SID != sid {
+		writeError(writer, http.StatusForbidden, "Students may only see their own grade report")
+		return
+	}
+	report, ok := s.reports[sid]
+	if !ok {
+		writeError(writer, http.StatusNotFound, "No grade report for SID "+strconv.Itoa(sid))
+		return
+	}
+	body, err := grades.MarshalReportJSON(report)
+	if err != nil {
+		writeError(writer, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(writer, json.RawMessage(body))
+}
+
+// handleAssignments serves the statistics of every assignment.
+func (s *server) handleAssignments(writer http.ResponseWriter, request *http.Request, principal principal) {
+	if !principal.Staff && !s.publicStats {
+		writeError(writer, http.StatusForbidden, "Only staff may see assignment statistics")
+		return
+	}
+	writeJSON(writer, s.assignmentStats)
+}
+
+// handleAssignment serves the statistics of the assignment at
+// /assignments/<name>.
+func (s *server) handleAssignment(writer http.ResponseWriter, request *http.Request, principal principal) {
+	if !principal.Staff && !s.publicStats {
+		writeError(writer, http.StatusForbidden, "Only staff may see assignment statistics")
+		return
+	}
+	name := strings.TrimPrefix(request.URL.Path, "/assignments/")
+	stats, ok := s.assignmentStats[name]
+	if !ok {
+		writeError(writer, http.StatusNotFound, "No assignment named "+name)
+		return
+	}
+	writeJSON(writer, stats)
+}
+
+// handleDistribution serves the distribution of grades in the course.
+func (s *server) handleDistribution(writer http.ResponseWriter, request *http.Request, principal principal) {
+	if !principal.Staff && !s.publicStats {
+		writeError(writer, http.StatusForbidden, "Only staff may see the grade distribution")
+		return
+	}
+	writeJSON(writer, s.distribution)
+}
+
+// writeJSON writes the value as a JSON response.
+func writeJSON(writer http.ResponseWriter, value interface{}) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		writeError(writer, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.Header().Set("Cache-Control", "no-store")
+	writer.Write(body)
+}
+
+// writeError writes an error message as a JSON response with the status code.
+func writeError(writer http.ResponseWriter, status int, message string) {
+	body, _ := json.Marshal(map[string]string{"error": message})
+	writer.Header().Set("Content-Type", "application/json")
+	writer.Header().Set("Cache-Control", "no-store")
+	writer.WriteHeader(status)
+	writer.Write(body)
+}
+
+func main() {
+	var reportsPath string
+	var addr string
+	var staffTokensPath string
+	var studentTokensPath string
+	var publicStats bool
+	flag.StringVar(&reportsPath, "reports", "", "JSON grade reports written by fromgradescope -json")
+	flag.StringVar(&addr, "addr", "localhost:8080", "Address to listen on")
+	flag.StringVar(&staffTokensPath, "staff-tokens", "", "File of staff tokens, one per line")
+	flag.StringVar(&studentTokensPath, "student-tokens", "", "CSV file of student tokens with SID and Token columns")
+	flag.BoolVar(&publicStats, "public-stats", false, "Allow students to see assignment statistics and the grade distribution")
+	flag.Parse()
+
+	if reportsPath == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	authenticators := make([]authenticator, 0)
+	if staffTokensPath != "" {
+		tokens, err := loadStaffTokens(staffTokensPath)
+		panicIfErr(err)
+		authenticators = append(authenticators, tokens)
+	}
+	if studentTokensPath != "" {
+		tokens, err := loadStudentTokens(studentTokensPath)
+		panicIfErr(err)
+		authenticators = append(authenticators, tokens)
+	}
+	if len(authenticators) == 0 {
+		panic(errors.New("At least one of -staff-tokens and -student-tokens is required"))
+	}
+
+	file, err := os.Open(reportsPath)
+	panicIfErr(err)
+	reports, err := grades.ReadReportsJSON(file)
+	file.Close()
+	panicIfErr(err)
+
+	httpServer := &http.Server{
+		Addr:         addr,
+		Handler:      newServer(reports, authenticators, publicStats).handler(),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+	log.Printf("Serving %d grade reports on %s", len(reports), addr)
+	log.Fatal(httpServer.ListenAndServe())
+}
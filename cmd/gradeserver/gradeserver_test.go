@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cs161-staff/grades"
+)
+
+func TestServer(t *testing.T) {
+	reports := map[int]*grades.GradeReport{
+		1: {
+			Student:     &grades.Student{SID: 1, Name: "Smith, Alice"},
+			TotalScore:  0.9,
+			Letter:      "A-",
+			Assignments: map[string]*grades.ReportAssignment{"HW 1": {Raw: 1, Adjusted: 0.9}},
+		},
+		2: {
+			Student:     &grades.Student{SID: 2, Name: "Jones, Bob"},
+			TotalScore:  0.7,
+			Letter:      "C-",
+			Assignments: map[string]*grades.ReportAssignment{"HW 1": {Raw: 0.5, Adjusted: 0.5}},
+		},
+	}
+	authenticators := []authenticator{
+		staffTokens{hashToken("staff"): true},
+		studentTokens{hashToken("alice"): 1},
+	}
+	handler := newServer(reports, authenticators, false).handler()
+
+	cases := []struct {
+		path   string
+		token  string
+		status int
+	}{
+		{"/students/1", "", http.StatusUnauthorized},
+		{"/students/1", "wrong", http.StatusUnauthorized},
+		{"/students/1", "alice", http.StatusOK},
+		{"/students/me", "alice", http.StatusOK},
+		{"/students/2", "alice", http.StatusForbidden},
+		{"/students/2", "staff", http.StatusOK},
+		{"/students/3", "staff", http.StatusNotFound},
+		{"/students", "alice", http.StatusForbidden},
+		{"/students", "staff", http.StatusOK},
+		{"/assignments/HW%201", "staff", http.StatusOK},
+		{"/assignments/HW%202", "staff", http.StatusNotFound},
+		{"/distribution", "alice", http.StatusForbidden},
+		{"/distribution", "staff", http.StatusOK},
+	}
+	for _, c := range cases {
+		request := httptest.NewRequest(http.MethodGet, c.path, nil)
+		if c.token != "" {
+			request.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		if recorder.Code != c.status {
+			t.Errorf("GET %s with token %q returned %d; expected %d", c.path, c.token, recorder.Code, c.status)
+		}
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/assignments/HW%201", nil)
+	request.Header.Set("Authorization", "Bearer staff")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	var stats assignmentStats
+	if err := json.Unmarshal(recorder.Body.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Raw.Count != 2 || stats.Raw.Mean != 0.75 || stats.Adjusted.Median != 0.7 {
+		t.Errorf("Got assignment statistics %+v", stats)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cs161-staff/grades"
+)
+
+// histogramBins is the number of equal-width bins in the distribution of
+// total scores.
+const histogramBins = 10
+
+// scoreStats is summary statistics of a set of scores from 0 to 1.
+type scoreStats struct {
+	Count  int     `json:"count"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	Stdev  float64 `json:"stdev"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+}
+
+// newScoreStats computes the statistics of the scores. The standard deviation
+// is the population standard deviation.
+func newScoreStats(scores []float64) scoreStats {
+	if len(scores) == 0 {
+		return scoreStats{}
+	}
+	sorted := make([]float64, len(scores))
+	copy(sorted, scores)
+	sort.Float64s(sorted)
+
+	stats := scoreStats{
+		Count: len(sorted),
+		Min:   sorted[0],
+		Max:   sorted[len(sorted)-1],
+	}
+	for _, score := range sorted {
+		stats.Mean += score
+	}
+	stats.Mean /= float64(len(sorted))
+	for _, score := range sorted {
+		stats.Stdev += (score - stats.Mean) * (score - stats.Mean)
+	}
+	stats.Stdev = math.Sqrt(stats.Stdev / float64(len(sorted)))
+	if middle := len(sorted) / 2; len(sorted)%2 == 1 {
+		stats.Median = sorted[middle]
+	} else {
+		stats.Median = (sorted[middle-1] + sorted[middle]) / 2.0
+	}
+	return stats
+}
+
+// assignmentStats is the statistics of the scores on an assignment.
+type assignmentStats struct {
+	Raw      scoreStats `json:"raw"`
+	Adjusted scoreStats `json:"adjusted"`
+}
+
+// histogramBin is a range of total scores and the number of students in it.
+type histogramBin struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Count int     `json:"count"`
+}
+
+// distribution is the distribution of grades in the course.
+type distribution struct {
+	Total     scoreStats     `json:"total"`
+	Letters   map[string]int `json:"letters"`
+	Histogram []histogramBin `json:"histogram"`
+}
+
+// newAssignmentStats computes the statistics of each assignment across the
+// reports.
+func newAssignmentStats(reports map[int]*grades.GradeReport) map[string]*assignmentStats {
+	raw := make(map[string][]float64)
+	adjusted := make(map[string][]float64)
+	for _, sid := range sortedSIDs(reports) {
+		for name, assignment := range reports[sid].Assignments {
+			raw[name] = append(raw[name], assignment.Raw)
+			adjusted[name] = append(adjusted[name], assignment.Adjusted)
+		}
+	}
+	stats := make(map[string]*assignmentStats, len(raw))
+	for name := range raw {
+		stats[name] = &assignmentStats{
+			Raw:      newScoreStats(raw[name]),
+			Adjusted: newScoreStats(adjusted[name]),
+		}
+	}
+	return stats
+}
+
+// newDistribution computes the distribution of total scores and letter grades
+// across the reports. Totals above 1 are counted in the highest histogram bin.
+func newDistribution(reports map[int]*grades.GradeReport) *distribution {
+	dist := &distribution{
+		Letters:   make(map[string]int),
+		Histogram: make([]histogramBin, histogramBins),
+	}
+	for i := range dist.Histogram {
+		dist.Histogram[i].Min = float64(i) / histogramBins
+		dist.Histogram[i].Max = float64(i+1) / histogramBins
+	}
+
+	totals := make([]float64, 0, len(reports))
+	for _, sid := range sortedSIDs(reports) {
+		report := reports[sid]
+		totals = append(totals, report.TotalScore)
+		if report.Letter != "" {
+			dist.Letters[report.Letter]++
+		}
+		bin := int(math.Floor(report.TotalScore * histogramBins))
+		if bin < 0 {
+			bin = 0
+		} else if bin >= histogramBins {
+			bin = histogramBins - 1
+		}
+		dist.Histogram[bin].Count++
+	}
+	dist.Total = newScoreStats(totals)
+	return dist
+}
+
+// sortedSIDs returns the SIDs of the reports in sorted order.
+func sortedSIDs(reports map[int]*grades.GradeReport) []int {
+	sids := make([]int, 0, len(reports))
+	for sid := range reports {
+		sids = append(sids, sid)
+	}
+	sort.Ints(sids)
+	return sids
+}
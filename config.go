@@ -0,0 +1,211 @@
+package grades
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CourseConfig is a course's grading configuration, declaring everything
+// needed to compute grades other than the students and their submissions.
+type CourseConfig struct {
+	// Categories is the course's categories, keyed by name.
+	Categories map[string]*Category
+
+	// Assignments is the course's assignments, keyed by name.
+	Assignments map[string]*Assignment
+
+	// GradeBins is the bins used to assign letter grades.
+	GradeBins GradeBins
+
+	// PassingLetter is the lowest letter grade that receives a P for students
+	// taking the course pass/no pass.
+	PassingLetter string
+
+	// SatisfactoryLetter is the lowest letter grade that receives an S for
+	// students taking the course satisfactory/unsatisfactory.
+	SatisfactoryLetter string
+
+	// Pipeline is the policies applied to each student, in order.
+	Pipeline Pipeline
+
+	// Requirements is the requirements that students must meet to receive
+	// their letter grade, applied in order after letter grades are assigned.
+	Requirements []*Requirement
+}
+
+// courseConfigFile is the format of a course configuration file.
+type courseConfigFile struct {
+	Categories []struct {
+		Name              string  `json:"name"`
+		Weight            float64 `json:"weight"`
+		Drops             int     `json:"drops"`
+		SlipDays          int     `json:"slip_days"`
+		HasLateMultiplier bool    `json:"has_late_multiplier"`
+	} `json:"categories"`
+	Assignments []struct {
+		Name      string    `json:"name"`
+		Category  string    `json:"category"`
+		MaxScore  float64   `json:"max_score"`
+		Weight    *float64  `json:"weight"`
+		SlipGroup *int      `json:"slip_group"`
+		Released  time.Time `json:"released"`
+		Due       time.Time `json:"due"`
+	} `json:"assignments"`
+	GradeBins []struct {
+		Letter string  `json:"letter"`
+		Min    float64 `json:"min"`
+	} `json:"grade_bins"`
+	PassingLetter      string   `json:"passing_letter"`
+	SatisfactoryLetter string   `json:"satisfactory_letter"`
+	Pipeline           Pipeline `json:"policies"`
+	Requirements       []struct {
+		Name         string          `json:"name"`
+		Type         RequirementType `json:"type"`
+		GradingBases []GradingBasis  `json:"grading_bases"`
+		Category     string          `json:"category"`
+		Assignment   string          `json:"assignment"`
+		MinScore     float64         `json:"min_score"`
+		MaxMissing   int             `json:"max_missing"`
+		MaxLetter    string          `json:"max_letter"`
+		Letter       string          `json:"letter"`
+	} `json:"requirements"`
+}
+
+// LoadCourseConfig loads the course configuration file at the given path. The
+// format is chosen by the file extension; only JSON is currently supported.
+func LoadCourseConfig(path string) (*CourseConfig, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+	case ".toml", ".yaml", ".yml":
+		return nil, fmt.Errorf("Course configuration format %s is not supported; use JSON", ext)
+	default:
+		return nil, fmt.Errorf("Unknown course configuration format %q", ext)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadCourseConfig(file)
+}
+
+// ReadCourseConfig reads a JSON course configuration from the reader and
+// validates it. The policies in the pipeline must already be registered, such
+// as by importing github.com/cs161-staff/grades/policies/all for the built-in
+// policies. If the configuration omits grade bins, DefaultGradeBins is used. An
+// assignment's weight defaults to 1, and its slip group defaults to -1, or no
+// slip group. An assignment's release and due times are optional, but if both
+// are given, it must be due after it is released. The passing and
+// satisfactory letter grades default to DefaultPassingLetter and
+// DefaultSatisfactoryLetter. Requirements must refer to the configuration's
+// categories, assignments and letter grades.
+func ReadCourseConfig(reader io.Reader) (*CourseConfig, error) {
+	var file courseConfigFile
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, err
+	}
+
+	config := &CourseConfig{
+		Categories:         make(map[string]*Category, len(file.Categories)),
+		Assignments:        make(map[string]*Assignment, len(file.Assignments)),
+		GradeBins:          DefaultGradeBins,
+		PassingLetter:      DefaultPassingLetter,
+		SatisfactoryLetter: DefaultSatisfactoryLetter,
+		Pipeline:           file.Pipeline,
+	}
+	for _, category := range file.Categories {
+		if category.Name == "" {
+			return nil, errors.New("Category without a name in course configuration")
+		}
+		if _, ok := config.Categories[category.Name]; ok {
+			return nil, errors.New("Duplicate category specified in course configuration: " + category.Name)
+		}
+		config.Categories[category.Name] = &Category{
+			Name:              category.Name,
+			Weight:            category.Weight,
+			Drops:             category.Drops,
+			SlipDays:          category.SlipDays,
+			HasLateMultiplier: category.HasLateMultiplier,
+		}
+	}
+	for _, assignment := range file.Assignments {
+		if assignment.Name == "" {
+			return nil, errors.New("Assignment without a name in course configuration")
+		}
+		if _, ok := config.Assignments[assignment.Name]; ok {
+			return nil, errors.New("Duplicate assignment specified in course configuration: " + assignment.Name)
+		}
+		if _, ok := config.Categories[assignment.Category]; !ok {
+			return nil, fmt.Errorf("Assignment %s references unknown category %s", assignment.Name, assignment.Category)
+		}
+		weight := 1.0
+		if assignment.Weight != nil {
+			weight = *assignment.Weight
+		}
+		slipGroup := -1
+		if assignment.SlipGroup != nil {
+			slipGroup = *assignment.SlipGroup
+		}
+		if !assignment.Released.IsZero() && !assignment.Due.IsZero() && !assignment.Due.After(assignment.Released) {
+			return nil, fmt.Errorf("Assignment %s is due before it is released", assignment.Name)
+		}
+		config.Assignments[assignment.Name] = &Assignment{
+			Name:         assignment.Name,
+			CategoryName: assignment.Category,
+			MaxScore:     assignment.MaxScore,
+			Weight:       weight,
+			SlipGroup:    slipGroup,
+			Released:     assignment.Released,
+			Due:          assignment.Due,
+		}
+	}
+	if len(file.GradeBins) > 0 {
+		config.GradeBins = make(GradeBins, len(file.GradeBins))
+		for i, bin := range file.GradeBins {
+			config.GradeBins[i] = GradeBin{Letter: bin.Letter, Min: bin.Min}
+		}
+	}
+	if file.PassingLetter != "" {
+		config.PassingLetter = file.PassingLetter
+		if _, ok := config.GradeBins.Min(file.PassingLetter); !ok {
+			return nil, fmt.Errorf("Passing letter grade %s is not in the grade bins", file.PassingLetter)
+		}
+	}
+	if file.SatisfactoryLetter != "" {
+		config.SatisfactoryLetter = file.SatisfactoryLetter
+		if _, ok := config.GradeBins.Min(file.SatisfactoryLetter); !ok {
+			return nil, fmt.Errorf("Satisfactory letter grade %s is not in the grade bins", file.SatisfactoryLetter)
+		}
+	}
+	for _, requirement := range file.Requirements {
+		config.Requirements = append(config.Requirements, &Requirement{
+			Name:         requirement.Name,
+			Type:         requirement.Type,
+			GradingBases: requirement.GradingBases,
+			Category:     requirement.Category,
+			Assignment:   requirement.Assignment,
+			MinScore:     requirement.MinScore,
+			MaxMissing:   requirement.MaxMissing,
+			MaxLetter:    requirement.MaxLetter,
+			Letter:       requirement.Letter,
+		})
+	}
+	for _, requirement := range config.Requirements {
+		if err := requirement.Validate(config); err != nil {
+			return nil, err
+		}
+	}
+	if err := config.Pipeline.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
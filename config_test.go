@@ -0,0 +1,72 @@
+package grades
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadCourseConfig(t *testing.T) {
+	config, err := ReadCourseConfig(strings.NewReader(`{
+		"categories": [{"name": "Homework", "weight": 1, "drops": 1}],
+		"assignments": [
+			{"name": "HW 1", "category": "Homework", "max_score": 10},
+			{"name": "HW 2", "category": "Homework", "max_score": 10, "weight": 2, "slip_group": 1}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if category := config.Categories["Homework"]; category.Weight != 1 || category.Drops != 1 {
+		t.Errorf("Got category %+v", category)
+	}
+	if hw1 := config.Assignments["HW 1"]; hw1.Weight != 1 || hw1.SlipGroup != -1 {
+		t.Errorf("Got weight %v and slip group %d for HW 1; expected defaults 1 and -1", hw1.Weight, hw1.SlipGroup)
+	}
+	if hw2 := config.Assignments["HW 2"]; hw2.Weight != 2 || hw2.SlipGroup != 1 {
+		t.Errorf("Got weight %v and slip group %d for HW 2; expected 2 and 1", hw2.Weight, hw2.SlipGroup)
+	}
+	if !reflect.DeepEqual(config.GradeBins, DefaultGradeBins) {
+		t.Errorf("Got grade bins %v; expected the default bins", config.GradeBins)
+	}
+	if config.PassingLetter != DefaultPassingLetter || config.SatisfactoryLetter != DefaultSatisfactoryLetter {
+		t.Errorf("Got passing letters %s and %s; expected the defaults", config.PassingLetter, config.SatisfactoryLetter)
+	}
+}
+
+func TestReadCourseConfigErrors(t *testing.T) {
+	cases := map[string]string{
+		"unknown field":        `{"categorys": []}`,
+		"unnamed category":     `{"categories": [{"weight": 1}]}`,
+		"duplicate category":   `{"categories": [{"name": "A"}, {"name": "A"}]}`,
+		"unnamed assignment":   `{"categories": [{"name": "A"}], "assignments": [{"category": "A"}]}`,
+		"duplicate assignment": `{"categories": [{"name": "A"}], "assignments": [{"name": "X", "category": "A"}, {"name": "X", "category": "A"}]}`,
+		"unknown category":     `{"assignments": [{"name": "X", "category": "A"}]}`,
+		"due before release":   `{"categories": [{"name": "A"}], "assignments": [{"name": "X", "category": "A", "released": "2021-09-02T00:00:00Z", "due": "2021-09-01T00:00:00Z"}]}`,
+		"unknown passing":      `{"passing_letter": "Z"}`,
+		"unknown policy":       `{"policies": [{"name": "no-such-policy"}]}`,
+	}
+	for name, config := range cases {
+		if _, err := ReadCourseConfig(strings.NewReader(config)); err == nil {
+			t.Errorf("Configuration with %s accepted", name)
+		}
+	}
+}
+
+func TestLoadCourseConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "course.json")
+	if err := os.WriteFile(path, []byte(`{"categories": [{"name": "A", "weight": 1}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCourseConfig(path); err != nil {
+		t.Error(err)
+	}
+	for _, name := range []string{"course.yaml", "course.txt", "missing.json"} {
+		if _, err := LoadCourseConfig(filepath.Join(dir, name)); err == nil {
+			t.Errorf("Loading %s did not fail", name)
+		}
+	}
+}
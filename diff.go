@@ -0,0 +1,250 @@
+package grades
+
+import (
+	"math"
+	"sort"
+)
+
+// scoreFields is the names of the scores compared for each category and
+// assignment, in the order their changes are listed.
+var scoreFields = []string{"Raw", "Adjusted", "Weighted"}
+
+// ScoreChange is a change in one of the scores of a category or assignment
+// between two grade reports.
+type ScoreChange struct {
+	// Name is the name of the category or assignment.
+	Name string
+
+	// Field is the score that changed: "Raw", "Adjusted" or "Weighted".
+	Field string
+
+	// Old is the score in the old report, from 0 to 1.
+	Old float64
+
+	// New is the score in the new report, from 0 to 1.
+	New float64
+}
+
+// ReportDiff is the difference between a student's old and new grade reports.
+type ReportDiff struct {
+	// SID is the student's student ID.
+	SID int
+
+	// Name is the student's name.
+	Name string
+
+	// Added is whether the student only has a new report.
+	Added bool
+
+	// Removed is whether the student only has an old report.
+	Removed bool
+
+	// OldTotal is the total score in the old report, from 0 to 1.
+	OldTotal float64
+
+	// NewTotal is the total score in the new report, from 0 to 1.
+	NewTotal float64
+
+	// OldLetter is the letter grade in the old report.
+	OldLetter string
+
+	// NewLetter is the letter grade in the new report.
+	NewLetter string
+
+	// OldGrade is the final grade for the grading basis in the old report.
+	OldGrade string
+
+	// NewGrade is the final grade for the grading basis in the new report.
+	NewGrade string
+
+	// Categories is the changes in category scores, sorted by name and then
+	// by field.
+	Categories []ScoreChange
+
+	// Assignments is the changes in assignment scores, sorted by name and then
+	// by field.
+	Assignments []ScoreChange
+
+	// RemovedComments is the comments only in the old report. Category and
+	// assignment comments are prefixed by their name.
+	RemovedComments []string
+
+	// AddedComments is the comments only in the new report. Category and
+	// assignment comments are prefixed by their name.
+	AddedComments []string
+}
+
+// TotalChange returns the change in the student's total score.
+func (diff *ReportDiff) TotalChange() float64 {
+	return diff.NewTotal - diff.OldTotal
+}
+
+// LetterChanged returns whether the student's letter grade changed.
+func (diff *ReportDiff) LetterChanged() bool {
+	return diff.OldLetter != diff.NewLetter
+}
+
+// GradeChanged returns whether the student's final grade changed.
+func (diff *ReportDiff) GradeChanged() bool {
+	return diff.OldGrade != diff.NewGrade
+}
+
+// DiffReports compares two sets of grade reports keyed by SID and returns the
+// differences for each student whose reports differ. Scores are considered
+// equal if they differ by no more than the tolerance. The differences are
+// sorted by impact: students whose letter or final grade changed come first,
+// followed by the largest changes in total score.
+func DiffReports(before map[int]*GradeReport, after map[int]*GradeReport, tolerance float64) []*ReportDiff {
+	sids := make(map[int]bool, len(before))
+	for sid := range before {
+		sids[sid] = true
+	}
+	for sid := range after {
+		sids[sid] = true
+	}
+
+	diffs := make([]*ReportDiff, 0)
+	for sid := range sids {
+		diff := diffReport(sid, before[sid], after[sid], tolerance)
+		if diff != nil {
+			diffs = append(diffs, diff)
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		a, b := diffs[i], diffs[j]
+		aGrade := a.LetterChanged() || a.GradeChanged()
+		bGrade := b.LetterChanged() || b.GradeChanged()
+		if aGrade != bGrade {
+			return aGrade
+		}
+		aChange, bChange := math.Abs(a.TotalChange()), math.Abs(b.TotalChange())
+		if aChange != bChange {
+			return aChange > bChange
+		}
+		return a.SID < b.SID
+	})
+	return diffs
+}
+
+// diffReport returns the difference between a student's reports before and
+// after, either of which may be nil, or nil if there is no difference.
+func diffReport(sid int, before *GradeReport, after *GradeReport, tolerance float64) *ReportDiff {
+	empty := &GradeReport{}
+	diff := &ReportDiff{
+		SID:     sid,
+		Added:   before == nil,
+		Removed: after == nil,
+	}
+	if before == nil {
+		before = empty
+	}
+	if after == nil {
+		after = empty
+	}
+	for _, report := range []*GradeReport{before, after} {
+		if report.Student != nil && report.Student.Name != "" {
+			diff.Name = report.Student.Name
+		}
+	}
+	diff.OldTotal, diff.NewTotal = before.TotalScore, after.TotalScore
+	diff.OldLetter, diff.NewLetter = before.Letter, after.Letter
+	diff.OldGrade, diff.NewGrade = before.Grade, after.Grade
+	diff.Categories = diffScores(categoryScores(before), categoryScores(after), tolerance)
+	diff.Assignments = diffScores(assignmentScores(before), assignmentScores(after), tolerance)
+	diff.RemovedComments, diff.AddedComments = diffComments(before.AllComments(), after.AllComments())
+
+	if !diff.Added && !diff.Removed && !diff.LetterChanged() && !diff.GradeChanged() &&
+		math.Abs(diff.TotalChange()) <= tolerance &&
+		len(diff.Categories) == 0 && len(diff.Assignments) == 0 &&
+		len(diff.RemovedComments) == 0 && len(diff.AddedComments) == 0 {
+		return nil
+	}
+	return diff
+}
+
+// categoryScores returns the raw, adjusted and weighted scores of each
+// category in the report, in the order of scoreFields.
+func categoryScores(report *GradeReport) map[string][]float64 {
+	scores := make(map[string][]float64, len(report.Categories))
+	for name, category := range report.Categories {
+		scores[name] = []float64{category.Raw, category.Adjusted, category.Weighted}
+	}
+	return scores
+}
+
+// assignmentScores returns the raw, adjusted and weighted scores of each
+// assignment in the report, in the order of scoreFields.
+func assignmentScores(report *GradeReport) map[string][]float64 {
+	scores := make(map[string][]float64, len(report.Assignments))
+	for name, assignment := range report.Assignments {
+		scores[name] = []float64{assignment.Raw, assignment.Adjusted, assignment.Weighted}
+	}
+	return scores
+}
+
+// diffScores returns the changes between the scores before and after, sorted
+// by name and then by field. A score missing from either side is treated as 0,
+// and every field of it is listed as changed.
+func diffScores(before map[string][]float64, after map[string][]float64, tolerance float64) []ScoreChange {
+	names := make([]string, 0, len(before))
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := make([]ScoreChange, 0)
+	for _, name := range names {
+		beforeScores, beforeOk := before[name]
+		afterScores, afterOk := after[name]
+		for i, field := range scoreFields {
+			var beforeScore, afterScore float64
+			if beforeOk {
+				beforeScore = beforeScores[i]
+			}
+			if afterOk {
+				afterScore = afterScores[i]
+			}
+			if beforeOk == afterOk && math.Abs(afterScore-beforeScore) <= tolerance {
+				continue
+			}
+			changes = append(changes, ScoreChange{
+				Name:  name,
+				Field: field,
+				Old:   beforeScore,
+				New:   afterScore,
+			})
+		}
+	}
+	return changes
+}
+
+// diffComments returns the comments only in before and the comments only in
+// after, counting repeated comments separately.
+func diffComments(before []string, after []string) ([]string, []string) {
+	counts := make(map[string]int, len(before))
+	for _, comment := range before {
+		counts[comment]++
+	}
+	added := make([]string, 0)
+	for _, comment := range after {
+		if counts[comment] > 0 {
+			counts[comment]--
+		} else {
+			added = append(added, comment)
+		}
+	}
+	removed := make([]string, 0)
+	for _, comment := range before {
+		if counts[comment] > 0 {
+			counts[comment]--
+			removed = append(removed, comment)
+		}
+	}
+	return removed, added
+}
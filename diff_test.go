@@ -0,0 +1,112 @@
+package grades
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDiffReports(t *testing.T) {
+	report := func(sid int, total float64, letter string, homework float64, comments ...string) *GradeReport {
+		return &GradeReport{
+			Student:     &Student{SID: sid, Name: "Student"},
+			TotalScore:  total,
+			Letter:      letter,
+			Categories:  map[string]*ReportCategory{"Homework": {Adjusted: homework}},
+			Assignments: map[string]*ReportAssignment{"HW 1": {Adjusted: homework, Comments: comments}},
+		}
+	}
+	before := map[int]*GradeReport{
+		1: report(1, 0.9, "A-", 0.9),
+		2: report(2, 0.8, "B-", 0.8),
+		3: report(3, 0.7, "C-", 0.7, "x0.9 (late)"),
+		4: report(4, 0.6, "D-", 0.6),
+	}
+	after := map[int]*GradeReport{
+		1: report(1, 0.9, "A-", 0.9),
+		2: report(2, 0.85, "B", 0.85),
+		3: report(3, 0.75, "C-", 0.75),
+		5: report(5, 0.5, "F", 0.5),
+	}
+
+	diffs := DiffReports(before, after, 1e-9)
+	sids := make([]int, len(diffs))
+	for i, diff := range diffs {
+		sids[i] = diff.SID
+	}
+	expected := []int{4, 5, 2, 3}
+	if len(sids) != len(expected) {
+		t.Fatalf("Got diffs for SIDs %v; expected %v", sids, expected)
+	}
+	for i := range sids {
+		if sids[i] != expected[i] {
+			t.Fatalf("Got diffs for SIDs %v; expected %v", sids, expected)
+		}
+	}
+
+	if !diffs[0].Removed || !diffs[1].Added {
+		t.Error("Removed and added students not marked")
+	}
+	diff := diffs[3]
+	if len(diff.Categories) != 1 || len(diff.Assignments) != 1 {
+		t.Errorf("Got %d category and %d assignment changes; expected 1 and 1", len(diff.Categories), len(diff.Assignments))
+	}
+	if len(diff.RemovedComments) != 1 || diff.RemovedComments[0] != "HW 1: x0.9 (late)" || len(diff.AddedComments) != 0 {
+		t.Errorf("Got removed comments %v and added comments %v", diff.RemovedComments, diff.AddedComments)
+	}
+}
+
+func TestDiffReportsFields(t *testing.T) {
+	report := func(raw float64, weighted float64, grade string) *GradeReport {
+		return &GradeReport{
+			Student:     &Student{SID: 1, Name: "Student"},
+			TotalScore:  0.9,
+			Letter:      "A-",
+			Grade:       grade,
+			Categories:  map[string]*ReportCategory{"Homework": {Raw: raw, Adjusted: 0.9, Weighted: weighted}},
+			Assignments: map[string]*ReportAssignment{"HW 1": {Raw: raw, Adjusted: 0.9, Weighted: weighted}},
+		}
+	}
+	before := map[int]*GradeReport{1: report(0.8, 0.5, "A-")}
+
+	diffs := DiffReports(before, map[int]*GradeReport{1: report(0.85, 0.5, "A-")}, 1e-9)
+	if len(diffs) != 1 || len(diffs[0].Categories) != 1 || diffs[0].Categories[0].Field != "Raw" || len(diffs[0].Assignments) != 1 || diffs[0].Assignments[0].Field != "Raw" {
+		t.Errorf("Change in raw scores not found: %+v", diffs)
+	}
+	diffs = DiffReports(before, map[int]*GradeReport{1: report(0.8, 0.6, "A-")}, 1e-9)
+	if len(diffs) != 1 || len(diffs[0].Categories) != 1 || diffs[0].Categories[0].Field != "Weighted" {
+		t.Errorf("Change in weighted scores not found: %+v", diffs)
+	}
+	diffs = DiffReports(before, map[int]*GradeReport{1: report(0.8, 0.5, "P")}, 1e-9)
+	if len(diffs) != 1 || !diffs[0].GradeChanged() || diffs[0].OldGrade != "A-" || diffs[0].NewGrade != "P" {
+		t.Errorf("Change in grade not found: %+v", diffs)
+	}
+	if diffs := DiffReports(before, map[int]*GradeReport{1: report(0.8, 0.5, "A-")}, 1e-9); len(diffs) != 0 {
+		t.Errorf("Got diffs for unchanged reports: %+v", diffs[0])
+	}
+}
+
+func TestReportsJSON(t *testing.T) {
+	reports := map[int]*GradeReport{
+		1: {
+			Student:     &Student{SID: 1, Name: "Student", Email: "student@berkeley.edu"},
+			TotalScore:  1.0 / 3.0,
+			Letter:      "F",
+			Categories:  map[string]*ReportCategory{"Homework": {Raw: 0.5, Adjusted: 0.5, Weighted: 1.0 / 3.0}},
+			Assignments: map[string]*ReportAssignment{"HW 1": {Raw: 0.5, Adjusted: 0.5, Weighted: 0.5, Comments: []string{"Regraded"}}},
+		},
+	}
+	var buffer bytes.Buffer
+	if err := WriteReportsJSON(&buffer, reports); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadReportsJSON(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diffs := DiffReports(reports, read, 0); len(diffs) != 0 {
+		t.Errorf("Reports changed after round trip through JSON: %+v", diffs[0])
+	}
+	if read[1].Student.Email != "student@berkeley.edu" {
+		t.Errorf("Got email %q after round trip through JSON", read[1].Student.Email)
+	}
+}
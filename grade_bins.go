@@ -0,0 +1,65 @@
+package grades
+
+// GradeBin is the minimum total score needed to receive a letter grade.
+type GradeBin struct {
+	// Letter is the letter grade of the bin.
+	Letter string
+
+	// Min is the minimum total score in the bin, from 0 to 1.
+	Min float64
+}
+
+// GradeBins is the set of bins that total scores are sorted into to assign
+// letter grades.
+type GradeBins []GradeBin
+
+// DefaultGradeBins is the standard absolute grading scale.
+var DefaultGradeBins = GradeBins{
+	{Letter: "A+", Min: 0.97},
+	{Letter: "A", Min: 0.93},
+	{Letter: "A-", Min: 0.90},
+	{Letter: "B+", Min: 0.87},
+	{Letter: "B", Min: 0.83},
+	{Letter: "B-", Min: 0.80},
+	{Letter: "C+", Min: 0.77},
+	{Letter: "C", Min: 0.73},
+	{Letter: "C-", Min: 0.70},
+	{Letter: "D+", Min: 0.67},
+	{Letter: "D", Min: 0.63},
+	{Letter: "D-", Min: 0.60},
+	{Letter: "F", Min: 0.0},
+}
+
+// Letter returns the letter grade of the bin with the highest minimum that the
+// total score meets. If the score is below every bin, the letter of the lowest
+// bin is returned.
+func (bins GradeBins) Letter(totalScore float64) string {
+	var best, lowest *GradeBin
+	for i := range bins {
+		bin := &bins[i]
+		if bin.Min <= totalScore && (best == nil || bin.Min > best.Min) {
+			best = bin
+		}
+		if lowest == nil || bin.Min < lowest.Min {
+			lowest = bin
+		}
+	}
+	if best != nil {
+		return best.Letter
+	}
+	if lowest != nil {
+		return lowest.Letter
+	}
+	return ""
+}
+
+// Min returns the minimum total score of the bin with the given letter and
+// whether such a bin exists.
+func (bins GradeBins) Min(letter string) (float64, bool) {
+	for _, bin := range bins {
+		if bin.Letter == letter {
+			return bin.Min, true
+		}
+	}
+	return 0.0, false
+}
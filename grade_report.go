@@ -1,5 +1,14 @@
 package grades
 
+import (
+	"sort"
+	"strconv"
+)
+
+// CategoryColumnPrefix is prepended to category names in the column headers of
+// exported grade reports to distinguish them from assignments of the same name.
+const CategoryColumnPrefix = "Category: "
+
 // ReportCategory is the representation of a Category on a Report.
 type ReportCategory struct {
 	// Raw is the raw score in the cateogry, from 0 to 1.
@@ -41,9 +50,58 @@ type GradeReport struct {
 	// TotalScore is the student's total score in the course, from 0 to 1.
 	TotalScore float64
 
+	// Letter is the student's letter grade, if one has been assigned.
+	Letter string
+
+	// Grade is the student's final grade for their grading basis, if one has
+	// been assigned: their letter grade, P or NP, or S or U.
+	Grade string
+
 	// Categories is the ReportCategories in the report.
 	Categories map[string]*ReportCategory
 
 	// Assignments is the ReqportAssignments in the report.
 	Assignments map[string]*ReportAssignment
+
+	// Comments is the human-readable comments on the report as a whole.
+	Comments []string
+
+	// Pending is the names of the assignments that are pending under an
+	// Incomplete grade, in sorted order.
+	Pending []string
+}
+
+// AllComments returns all of the comments in the report: the assignment
+// comments and then the category comments, each prefixed by their name and in
+// sorted order, followed by the report's own comments.
+func (report *GradeReport) AllComments() []string {
+	assignmentNames := make([]string, 0, len(report.Assignments))
+	for name := range report.Assignments {
+		assignmentNames = append(assignmentNames, name)
+	}
+	sort.Strings(assignmentNames)
+	categoryNames := make([]string, 0, len(report.Categories))
+	for name := range report.Categories {
+		categoryNames = append(categoryNames, name)
+	}
+	sort.Strings(categoryNames)
+
+	comments := make([]string, 0)
+	for _, name := range assignmentNames {
+		for _, comment := range report.Assignments[name].Comments {
+			comments = append(comments, name+": "+comment)
+		}
+	}
+	for _, name := range categoryNames {
+		for _, comment := range report.Categories[name].Comments {
+			comments = append(comments, name+": "+comment)
+		}
+	}
+	return append(comments, report.Comments...)
+}
+
+// FormatPercent formats a score from 0 to 1 as a percentage rounded to the
+// given number of decimal places, without a percent sign.
+func FormatPercent(score float64, rounding int) string {
+	return strconv.FormatFloat(score*100.0, 'f', rounding, 64)
 }
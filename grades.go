@@ -22,3 +22,20 @@ func (roster Roster) ApplyPolicy(policy Policy) *Roster {
 	}
 	return &newRoster
 }
+
+// Finalize generates a grade report for every outcome in the roster and
+// returns the report with the highest total score for each key. Ties go to the
+// earliest outcome, so policies should return outcomes in a deterministic
+// order.
+func (roster Roster) Finalize() map[int]*GradeReport {
+	reports := make(map[int]*GradeReport, len(roster))
+	for key, outcomes := range roster {
+		for _, outcome := range outcomes {
+			report := outcome.GenerateGradeReport()
+			if best, ok := reports[key]; !ok || report.TotalScore > best.TotalScore {
+				reports[key] = report
+			}
+		}
+	}
+	return reports
+}
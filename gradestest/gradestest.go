@@ -0,0 +1,331 @@
+// Package gradestest provides golden-file regression tests for course
+// configurations.
+//
+// A test case is a directory containing three files:
+//   - course.json is the course configuration, as read by
+//     grades.LoadCourseConfig.
+//   - students.json is the student fixtures, a JSON array of students and
+//     their submissions.
+//   - expected.json is the expected grade reports, as written by
+//     grades.WriteReportsJSON.
+//
+// Run computes the grade reports for the students with the course's full
+// pipeline and compares them to the expected reports. Running the tests with
+// the -gradestest.update flag regenerates expected.json instead.
+package gradestest
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cs161-staff/grades"
+	_ "github.com/cs161-staff/grades/policies/all"
+)
+
+// Tolerance is the largest difference between an expected and actual score
+// that is not reported as a failure.
+const Tolerance = 1e-9
+
+// File names in a test case directory.
+const (
+	CourseFile   = "course.json"
+	StudentsFile = "students.json"
+	ExpectedFile = "expected.json"
+)
+
+var update = flag.Bool("gradestest.update", false, "Regenerate the expected grade reports of golden-file tests")
+
+// studentFixture is the JSON format of a student in the student fixtures.
+type studentFixture struct {
+	SID   int     `json:"sid"`
+	Name  string  `json:"name"`
+	Email string  `json:"email"`
+	Units float64 `json:"units"`
+
+	// GradingBasis is a CalCentral grading basis, and defaults to a letter
+	// grade.
+	GradingBasis grades.GradingBasis `json:"grading_basis"`
+
+	Submissions map[string]submissionFixture `json:"submissions"`
+}
+
+// submissionFixture is the JSON format of a submission in the student
+// fixtures. Assignments without a submission are missing.
+type submissionFixture struct {
+	Score float64 `json:"score"`
+
+	// Status is "graded", "ungraded", "missing" or "pending", and defaults to
+	// "graded".
+	Status string `json:"status"`
+
+	// Lateness is a duration in the format of time.ParseDuration.
+	Lateness string `json:"lateness"`
+
+	Dropped  bool     `json:"dropped"`
+	Comments []string `json:"comments"`
+}
+
+// statuses is the submission statuses by name.
+var statuses = map[string]grades.SubmissionStatus{
+	"":         grades.StatusGraded,
+	"graded":   grades.StatusGraded,
+	"ungraded": grades.StatusUngraded,
+	"missing":  grades.StatusMissing,
+	"pending":  grades.StatusPending,
+}
+
+// LoadStudents loads the student fixtures at the given path and returns a
+// roster with one outcome for each student.
+func LoadStudents(path string, course *grades.CourseConfig) (grades.Roster, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var fixtures []studentFixture
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&fixtures); err != nil {
+		return nil, err
+	}
+
+	roster := make(grades.Roster, len(fixtures))
+	for _, fixture := range fixtures {
+		if _, ok := roster[fixture.SID]; ok {
+			return nil, fmt.Errorf("Duplicate student fixture for SID %d", fixture.SID)
+		}
+		submissions := make(map[string]grades.AssignmentSubmission, len(fixture.Submissions))
+		for name, submission := range fixture.Submissions {
+			if _, ok := course.Assignments[name]; !ok {
+				return nil, fmt.Errorf("Student %d has a submission for unknown assignment %s", fixture.SID, name)
+			}
+			status, ok := statuses[submission.Status]
+			if !ok {
+				return nil, fmt.Errorf("Student %d has unknown status %q for %s", fixture.SID, submission.Status, name)
+			}
+			var lateness time.Duration
+			if submission.Lateness != "" {
+				lateness, err = time.ParseDuration(submission.Lateness)
+				if err != nil {
+					return nil, fmt.Errorf("Student %d has invalid lateness for %s: %w", fixture.SID, name, err)
+				}
+			}
+			submissions[name] = grades.AssignmentSubmission{
+				Score:    submission.Score,
+				Status:   status,
+				Lateness: lateness,
+				Dropped:  submission.Dropped,
+				Comments: submission.Comments,
+			}
+		}
+		student := grades.NewStudent(fixture.SID, fixture.Name, course.Categories, course.Assignments, submissions)
+		student.Email = fixture.Email
+		student.Units = fixture.Units
+		student.GradingBasis = fixture.GradingBasis
+		roster[fixture.SID] = []*grades.Student{student}
+	}
+	return roster, nil
+}
+
+// Compute computes the finalized grade reports, with letter and final grades,
+// of the test case in the given directory.
+func Compute(dir string) (map[int]*grades.GradeReport, error) {
+	course, err := grades.LoadCourseConfig(filepath.Join(dir, CourseFile))
+	if err != nil {
+		return nil, err
+	}
+	roster, err := LoadStudents(filepath.Join(dir, StudentsFile), course)
+	if err != nil {
+		return nil, err
+	}
+	roster, err = course.Pipeline.Apply(roster)
+	if err != nil {
+		return nil, err
+	}
+	reports := roster.Finalize()
+	for _, report := range reports {
+		if err := course.AssignGrade(report); err != nil {
+			return nil, err
+		}
+	}
+	return reports, nil
+}
+
+// Run runs the golden-file test case in the given directory. If the
+// -gradestest.update flag is set, the expected reports are overwritten with
+// the computed reports.
+func Run(t testing.TB, dir string) {
+	t.Helper()
+
+	actual, err := Compute(dir)
+	if err != nil {
+		t.Fatalf("%s: %v", dir, err)
+	}
+
+	expectedPath := filepath.Join(dir, ExpectedFile)
+	if *update {
+		file, err := os.Create(expectedPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = grades.WriteReportsJSON(file, actual)
+		file.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("Updated %s", expectedPath)
+		return
+	}
+
+	file, err := os.Open(expectedPath)
+	if err != nil {
+		t.Fatalf("%v (run with -gradestest.update to create it)", err)
+	}
+	expected, err := grades.ReadReportsJSON(file)
+	file.Close()
+	if err != nil {
+		t.Fatalf("%s: %v", expectedPath, err)
+	}
+
+	if differences := Compare(expected, actual); len(differences) > 0 {
+		t.Errorf("%s: grade reports differ from %s (run with -gradestest.update to accept them):\n%s", dir, ExpectedFile, strings.Join(differences, "\n"))
+	}
+}
+
+// Compare compares the expected and actual grade reports field by field and
+// returns a human-readable description of each difference, sorted by SID.
+// Scores are compared within Tolerance.
+func Compare(expected map[int]*grades.GradeReport, actual map[int]*grades.GradeReport) []string {
+	sids := make([]int, 0, len(expected))
+	for sid := range expected {
+		sids = append(sids, sid)
+	}
+	for sid := range actual {
+		if _, ok := expected[sid]; !ok {
+			sids = append(sids, sid)
+		}
+	}
+	sort.Ints(sids)
+
+	differences := make([]string, 0)
+	for _, sid := range sids {
+		expectedReport, expectedOk := expected[sid]
+		actualReport, actualOk := actual[sid]
+		if !actualOk {
+			differences = append(differences, fmt.Sprintf("SID %d: missing report", sid))
+			continue
+		}
+		if !expectedOk {
+			differences = append(differences, fmt.Sprintf("SID %d: unexpected report", sid))
+			continue
+		}
+		for _, difference := range compareReport(expectedReport, actualReport) {
+			differences = append(differences, fmt.Sprintf("SID %d: %s", sid, difference))
+		}
+	}
+	return differences
+}
+
+// compareReport returns the differences between two reports for the same
+// student.
+func compareReport(expected *grades.GradeReport, actual *grades.GradeReport) []string {
+	differences := make([]string, 0)
+	if expected.Student.Name != actual.Student.Name {
+		differences = append(differences, fmt.Sprintf("Name: expected %q, got %q", expected.Student.Name, actual.Student.Name))
+	}
+	differences = append(differences, compareScore("TotalScore", expected.TotalScore, actual.TotalScore)...)
+	if expected.Letter != actual.Letter {
+		differences = append(differences, fmt.Sprintf("Letter: expected %q, got %q", expected.Letter, actual.Letter))
+	}
+	if expected.Grade != actual.Grade {
+		differences = append(differences, fmt.Sprintf("Grade: expected %q, got %q", expected.Grade, actual.Grade))
+	}
+	differences = append(differences, compareComments("Comments", expected.Comments, actual.Comments)...)
+	if strings.Join(expected.Pending, ", ") != strings.Join(actual.Pending, ", ") {
+		differences = append(differences, fmt.Sprintf("Pending: expected %v, got %v", expected.Pending, actual.Pending))
+	}
+
+	categoryNames := make(map[string]bool, len(expected.Categories))
+	for name := range expected.Categories {
+		categoryNames[name] = true
+	}
+	for name := range actual.Categories {
+		categoryNames[name] = true
+	}
+	for _, name := range sortedKeys(categoryNames) {
+		field := fmt.Sprintf("Categories[%s]", name)
+		expectedCategory, expectedOk := expected.Categories[name]
+		actualCategory, actualOk := actual.Categories[name]
+		if !expectedOk || !actualOk {
+			differences = append(differences, fmt.Sprintf("%s: expected present %t, got present %t", field, expectedOk, actualOk))
+			continue
+		}
+		differences = append(differences, compareScore(field+".Raw", expectedCategory.Raw, actualCategory.Raw)...)
+		differences = append(differences, compareScore(field+".Adjusted", expectedCategory.Adjusted, actualCategory.Adjusted)...)
+		differences = append(differences, compareScore(field+".Weighted", expectedCategory.Weighted, actualCategory.Weighted)...)
+		differences = append(differences, compareComments(field+".Comments", expectedCategory.Comments, actualCategory.Comments)...)
+	}
+
+	assignmentNames := make(map[string]bool, len(expected.Assignments))
+	for name := range expected.Assignments {
+		assignmentNames[name] = true
+	}
+	for name := range actual.Assignments {
+		assignmentNames[name] = true
+	}
+	for _, name := range sortedKeys(assignmentNames) {
+		field := fmt.Sprintf("Assignments[%s]", name)
+		expectedAssignment, expectedOk := expected.Assignments[name]
+		actualAssignment, actualOk := actual.Assignments[name]
+		if !expectedOk || !actualOk {
+			differences = append(differences, fmt.Sprintf("%s: expected present %t, got present %t", field, expectedOk, actualOk))
+			continue
+		}
+		differences = append(differences, compareScore(field+".Raw", expectedAssignment.Raw, actualAssignment.Raw)...)
+		differences = append(differences, compareScore(field+".Adjusted", expectedAssignment.Adjusted, actualAssignment.Adjusted)...)
+		differences = append(differences, compareScore(field+".Weighted", expectedAssignment.Weighted, actualAssignment.Weighted)...)
+		differences = append(differences, compareComments(field+".Comments", expectedAssignment.Comments, actualAssignment.Comments)...)
+	}
+
+	return differences
+}
+
+// compareScore returns the difference between two scores, if any.
+func compareScore(field string, expected float64, actual float64) []string {
+	if math.Abs(expected-actual) <= Tolerance {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s: expected %v, got %v", field, expected, actual)}
+}
+
+// compareComments returns the difference between two lists of comments, if
+// any.
+func compareComments(field string, expected []string, actual []string) []string {
+	equal := len(expected) == len(actual)
+	for i := 0; equal && i < len(expected); i++ {
+		equal = expected[i] == actual[i]
+	}
+	if equal {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s: expected %q, got %q", field, expected, actual)}
+}
+
+// sortedKeys returns the keys of the set in sorted order.
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,9 @@
+package gradestest
+
+import (
+	"testing"
+)
+
+func TestBasic(t *testing.T) {
+	Run(t, "testdata/basic")
+}
@@ -0,0 +1,100 @@
+package grades
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GradingBasis is the basis on which a student takes the course, which
+// determines how their letter grade is converted to their final grade.
+type GradingBasis int
+
+const (
+	// BasisLetter is a student who receives a letter grade.
+	BasisLetter GradingBasis = iota
+
+	// BasisPassNoPass is a student who receives P or NP.
+	BasisPassNoPass
+
+	// BasisSatisfactory is a student who receives S or U.
+	BasisSatisfactory
+)
+
+// Default letter grades at or above which students pass when taking the
+// course on a pass/no pass or satisfactory/unsatisfactory basis.
+const (
+	DefaultPassingLetter      = "C-"
+	DefaultSatisfactoryLetter = "B"
+)
+
+// ParseGradingBasis parses a grading basis as written in the CalCentral
+// roster. An empty grading basis is a letter grade.
+func ParseGradingBasis(value string) (GradingBasis, error) {
+	switch strings.ToUpper(strings.TrimSpace(value)) {
+	case "", "GRD", "LTR", "LETTER":
+		return BasisLetter, nil
+	case "PNP", "P/NP", "EPN":
+		return BasisPassNoPass, nil
+	case "SUS", "S/U", "ESU":
+		return BasisSatisfactory, nil
+	default:
+		return BasisLetter, fmt.Errorf("Unknown grading basis %q", value)
+	}
+}
+
+// String returns the CalCentral code of the grading basis.
+func (basis GradingBasis) String() string {
+	switch basis {
+	case BasisLetter:
+		return "GRD"
+	case BasisPassNoPass:
+		return "PNP"
+	case BasisSatisfactory:
+		return "SUS"
+	default:
+		return fmt.Sprintf("GradingBasis(%d)", int(basis))
+	}
+}
+
+// MarshalText encodes the grading basis as its CalCentral code.
+func (basis GradingBasis) MarshalText() ([]byte, error) {
+	return []byte(basis.String()), nil
+}
+
+// UnmarshalText decodes a grading basis with ParseGradingBasis.
+func (basis *GradingBasis) UnmarshalText(text []byte) error {
+	parsed, err := ParseGradingBasis(string(text))
+	if err != nil {
+		return err
+	}
+	*basis = parsed
+	return nil
+}
+
+// Grade returns the final grade of a student with the given letter grade and
+// grading basis: the letter grade itself, P or NP, or S or U.
+func (config *CourseConfig) Grade(letter string, basis GradingBasis) (string, error) {
+	var threshold, pass, noPass string
+	switch basis {
+	case BasisLetter:
+		return letter, nil
+	case BasisPassNoPass:
+		threshold, pass, noPass = config.PassingLetter, "P", "NP"
+	case BasisSatisfactory:
+		threshold, pass, noPass = config.SatisfactoryLetter, "S", "U"
+	default:
+		return "", fmt.Errorf("Unknown grading basis %v", basis)
+	}
+	min, ok := config.GradeBins.Min(letter)
+	if !ok {
+		return "", fmt.Errorf("Letter grade %q is not in the grade bins", letter)
+	}
+	thresholdMin, ok := config.GradeBins.Min(threshold)
+	if !ok {
+		return "", fmt.Errorf("Passing letter grade %q is not in the grade bins", threshold)
+	}
+	if min >= thresholdMin {
+		return pass, nil
+	}
+	return noPass, nil
+}
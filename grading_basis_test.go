@@ -0,0 +1,37 @@
+package grades
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGrade(t *testing.T) {
+	config, err := ReadCourseConfig(strings.NewReader(`{"passing_letter": "C"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		letter string
+		basis  string
+		grade  string
+	}{
+		{"C-", "GRD", "C-"},
+		{"C-", "", "C-"},
+		{"C", "P/NP", "P"},
+		{"C-", "EPN", "NP"},
+		{"B", "SUS", "S"},
+		{"B-", "S/U", "U"},
+	}
+	for _, c := range cases {
+		basis, err := ParseGradingBasis(c.basis)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if grade, err := config.Grade(c.letter, basis); err != nil || grade != c.grade {
+			t.Errorf("Grade(%s, %s) returned %q, %v; expected %q", c.letter, c.basis, grade, err, c.grade)
+		}
+	}
+	if _, err := ParseGradingBasis("audit"); err == nil {
+		t.Error("Unknown grading basis accepted")
+	}
+}
@@ -0,0 +1,45 @@
+package grades
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// IncompleteGrade is the final grade of a student with pending assignments.
+const IncompleteGrade = "I"
+
+// assignIncomplete sets the final grade of a report with pending assignments
+// to IncompleteGrade, adding a comment listing what remains. It returns
+// whether the report has pending assignments.
+func assignIncomplete(report *GradeReport) bool {
+	if len(report.Pending) == 0 {
+		return false
+	}
+	report.Grade = IncompleteGrade
+	report.Comments = append(report.Comments, fmt.Sprintf("Incomplete: %s pending", strings.Join(report.Pending, ", ")))
+	return true
+}
+
+// MergeIncompletes returns the previous grade reports with the reports of
+// students who had an Incomplete grade replaced by their current reports, so
+// that late-arriving scores can be merged in without changing any other
+// student's grade. Students with an Incomplete grade who have no current
+// report keep their previous report. It also returns the SIDs of the students
+// whose reports were replaced.
+func MergeIncompletes(previous map[int]*GradeReport, current map[int]*GradeReport) (map[int]*GradeReport, []int) {
+	merged := make(map[int]*GradeReport, len(previous))
+	replaced := make([]int, 0)
+	for sid, report := range previous {
+		merged[sid] = report
+		if report.Grade != IncompleteGrade {
+			continue
+		}
+		if currentReport, ok := current[sid]; ok {
+			merged[sid] = currentReport
+			replaced = append(replaced, sid)
+		}
+	}
+	sort.Ints(replaced)
+	return merged, replaced
+}
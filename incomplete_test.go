@@ -0,0 +1,46 @@
+package grades
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIncomplete(t *testing.T) {
+	student := &Student{
+		SID:        1,
+		Categories: map[string]*Category{"Homework": {Name: "Homework", Weight: 1}},
+		Assignments: map[string]*Assignment{
+			"HW 1": {Name: "HW 1", CategoryName: "Homework", MaxScore: 10, Weight: 1, Grade: AssignmentSubmission{Score: 8}},
+			"HW 2": {Name: "HW 2", CategoryName: "Homework", MaxScore: 10, Weight: 1, Grade: AssignmentSubmission{Status: StatusPending}},
+		},
+	}
+	report := student.GenerateGradeReport()
+	if report.TotalScore != 0.8 {
+		t.Errorf("Got total %v; expected pending assignment to be left out", report.TotalScore)
+	}
+	config := &CourseConfig{GradeBins: DefaultGradeBins}
+	if err := config.AssignGrade(report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Grade != IncompleteGrade || !reflect.DeepEqual(report.Pending, []string{"HW 2"}) {
+		t.Errorf("Got grade %q with pending %v", report.Grade, report.Pending)
+	}
+
+	// A category with only pending assignments counts as 0 with a comment.
+	student.Categories["Exams"] = &Category{Name: "Exams", Weight: 1}
+	student.Assignments["Final"] = &Assignment{Name: "Final", CategoryName: "Exams", MaxScore: 100, Weight: 1, Grade: AssignmentSubmission{Status: StatusPending}}
+	pendingReport := student.GenerateGradeReport()
+	if exams := pendingReport.Categories["Exams"]; exams.Weighted != 0 || len(exams.Comments) != 1 || pendingReport.TotalScore != 0.8 {
+		t.Errorf("Got exams category %+v and total %v for a fully pending category", exams, pendingReport.TotalScore)
+	}
+	if homework := pendingReport.Categories["Homework"]; len(homework.Comments) != 0 {
+		t.Errorf("Got comments %v on a partly pending category", homework.Comments)
+	}
+
+	previous := map[int]*GradeReport{1: report, 2: {Grade: "A"}}
+	current := map[int]*GradeReport{1: {Grade: "B"}, 2: {Grade: "C"}}
+	merged, replaced := MergeIncompletes(previous, current)
+	if merged[1].Grade != "B" || merged[2].Grade != "A" || !reflect.DeepEqual(replaced, []int{1}) {
+		t.Errorf("Got merged grades %q and %q, replacing %v", merged[1].Grade, merged[2].Grade, replaced)
+	}
+}
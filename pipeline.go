@@ -0,0 +1,156 @@
+package grades
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// PolicyMaker constructs a policy from its configuration parameters. The roster
+// is the roster that the policy will be applied to, for policies that depend on
+// every student.
+type PolicyMaker func(params json.RawMessage, roster Roster) (Policy, error)
+
+// registration is a policy in the registry.
+type registration struct {
+	maker           PolicyMaker
+	after           []string
+	dependsOnRoster bool
+}
+
+// registry is the registered policies by name.
+var registry = make(map[string]*registration)
+
+// RegisterPolicy registers a policy constructor under the given name, so that
+// pipelines can refer to it. If a pipeline contains both the policy and any of
+// the policies named in after, the policy must come after them. Policy
+// packages register themselves when imported. RegisterPolicy panics if the name
+// is already registered.
+func RegisterPolicy(name string, maker PolicyMaker, after ...string) {
+	register(name, &registration{
+		maker: maker,
+		after: after,
+	})
+}
+
+// RegisterRosterPolicy is like RegisterPolicy, but for policies whose effect on
+// a student depends on the other students in the roster, such as a policy
+// that uses the class average. Caches recompute every student when any
+// student changes if a pipeline contains such a policy.
+func RegisterRosterPolicy(name string, maker PolicyMaker, after ...string) {
+	register(name, &registration{
+		maker:           maker,
+		after:           after,
+		dependsOnRoster: true,
+	})
+}
+
+func register(name string, policy *registration) {
+	if _, ok := registry[name]; ok {
+		panic("Policy registered twice: " + name)
+	}
+	registry[name] = policy
+}
+
+// RegisteredPolicies returns the names of the registered policies in sorted
+// order.
+func RegisteredPolicies() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Stage is a named policy in a pipeline and its parameters.
+type Stage struct {
+	// Name is the name of the registered policy.
+	Name string `json:"name"`
+
+	// Params is the policy's parameters, whose format depends on the policy.
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// Pipeline is an ordered list of stages applied to a roster.
+type Pipeline []Stage
+
+// Validate returns an error if the pipeline refers to a policy that is not
+// registered or orders two policies in a way that is known to be invalid.
+func (pipeline Pipeline) Validate() error {
+	for i, stage := range pipeline {
+		policy, ok := registry[stage.Name]
+		if !ok {
+			return fmt.Errorf("Unknown policy %s; import the package that registers it, or github.com/cs161-staff/grades/policies/all for the built-in policies", stage.Name)
+		}
+		for _, later := range pipeline[i+1:] {
+			for _, after := range policy.after {
+				if later.Name == after {
+					return fmt.Errorf("Policy %s must come after %s", stage.Name, after)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Insert returns a copy of the pipeline with the stages inserted in order, each
+// as early as possible while still coming after every stage that its policy
+// must come after and every existing stage of the same policy, so that an
+// inserted stage takes precedence over the existing ones. Insert returns an error if the resulting pipeline is not
+// valid, such as when an existing stage must come after an inserted one.
+func (pipeline Pipeline) Insert(stages ...Stage) (Pipeline, error) {
+	inserted := append(Pipeline{}, pipeline...)
+	earliest := 0
+	for _, stage := range stages {
+		position := earliest
+		for i, existing := range inserted {
+			if existing.Name == stage.Name && i >= position {
+				position = i + 1
+			}
+		}
+		if policy, ok := registry[stage.Name]; ok {
+			for i, existing := range inserted {
+				for _, after := range policy.after {
+					if existing.Name == after && i >= position {
+						position = i + 1
+					}
+				}
+			}
+		}
+		inserted = append(inserted[:position], append(Pipeline{stage}, inserted[position:]...)...)
+		earliest = position + 1
+	}
+	if err := inserted.Validate(); err != nil {
+		return nil, err
+	}
+	return inserted, nil
+}
+
+// DependsOnRoster returns whether any of the pipeline's policies were
+// registered with RegisterRosterPolicy.
+func (pipeline Pipeline) DependsOnRoster() bool {
+	for _, stage := range pipeline {
+		if policy, ok := registry[stage.Name]; ok && policy.dependsOnRoster {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply validates the pipeline and applies each stage to the roster in order.
+// Each stage's policy is constructed from its parameters just before it is
+// applied.
+func (pipeline Pipeline) Apply(roster Roster) (Roster, error) {
+	if err := pipeline.Validate(); err != nil {
+		return nil, err
+	}
+	for _, stage := range pipeline {
+		policy, err := registry[stage.Name].maker(stage.Params, roster)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid parameters for policy %s: %w", stage.Name, err)
+		}
+		roster = *roster.ApplyPolicy(policy)
+	}
+	return roster, nil
+}
@@ -0,0 +1,74 @@
+package grades
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func init() {
+	RegisterPolicy("test-first", func(params json.RawMessage, roster Roster) (Policy, error) {
+		return func(student *Student) []*Student {
+			return []*Student{student, student}
+		}, nil
+	})
+	RegisterPolicy("test-second", func(params json.RawMessage, roster Roster) (Policy, error) {
+		return func(student *Student) []*Student {
+			return []*Student{student}
+		}, nil
+	}, "test-first")
+}
+
+func TestPipelineValidate(t *testing.T) {
+	if err := (Pipeline{{Name: "test-first"}, {Name: "test-second"}}).Validate(); err != nil {
+		t.Errorf("Valid pipeline rejected: %v", err)
+	}
+	if err := (Pipeline{{Name: "test-second"}, {Name: "test-first"}}).Validate(); err == nil {
+		t.Error("Pipeline with invalid order accepted")
+	}
+	if err := (Pipeline{{Name: "test-unknown"}}).Validate(); err == nil {
+		t.Error("Pipeline with unknown policy accepted")
+	}
+}
+
+func TestPipelineInsert(t *testing.T) {
+	pipeline := Pipeline{{Name: "test-first"}, {Name: "test-second"}}
+	inserted, err := pipeline.Insert(Stage{Name: "test-second"}, Stage{Name: "test-first"})
+	if err == nil {
+		t.Errorf("Insert built the invalid pipeline %v", inserted)
+	}
+	if _, err := pipeline.Insert(Stage{Name: "test-unknown"}); err == nil {
+		t.Error("Insert accepted an unknown policy")
+	}
+
+	pipeline = Pipeline{{Name: "test-first"}, {Name: "test-second"}}
+	inserted, err = pipeline.Insert(Stage{Name: "test-first", Params: json.RawMessage("1")}, Stage{Name: "test-second"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, len(inserted))
+	for i, stage := range inserted {
+		names[i] = stage.Name
+	}
+	// Inserted stages come after the existing stages of the same policy.
+	expected := "[test-first test-first test-second test-second]"
+	if fmt.Sprint(names) != expected || string(inserted[1].Params) != "1" {
+		t.Errorf("Inserted pipeline is %v; expected %s", names, expected)
+	}
+	if len(pipeline) != 2 || pipeline[1].Name != "test-second" {
+		t.Errorf("Insert changed the original pipeline to %v", pipeline)
+	}
+}
+
+func TestPipelineApply(t *testing.T) {
+	roster := Roster{1: {{SID: 1}}, 2: {{SID: 2}}}
+	roster, err := (Pipeline{{Name: "test-first"}, {Name: "test-first"}, {Name: "test-second"}}).Apply(roster)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, outcomes := range roster {
+		if len(outcomes) != 4 {
+			t.Errorf("Student %d has %d outcomes; expected 4", key, len(outcomes))
+		}
+	}
+}
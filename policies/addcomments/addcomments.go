@@ -1,9 +1,21 @@
 package addcomments
 
 import (
+	"encoding/json"
+
 	"github.com/cs161-staff/grades"
 )
 
+func init() {
+	grades.RegisterPolicy("addcomments", func(rawParams json.RawMessage, roster grades.Roster) (grades.Policy, error) {
+		var params map[int]map[string][]string
+		if err := json.Unmarshal(rawParams, &params); err != nil {
+			return nil, err
+		}
+		return Make(params), nil
+	})
+}
+
 // Make takes in a student ID -> assignment name -> comments map and returns a
 // policy that adds those comments to the specified students.
 func Make(comments map[int]map[string][]string) grades.Policy {
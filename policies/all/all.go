@@ -0,0 +1,17 @@
+// Package all imports every policy package so that all policies are
+// registered for use in pipelines.
+package all
+
+import (
+	_ "github.com/cs161-staff/grades/policies/addcomments"
+	_ "github.com/cs161-staff/grades/policies/changedrops"
+	_ "github.com/cs161-staff/grades/policies/changeslipdays"
+	_ "github.com/cs161-staff/grades/policies/clobber"
+	_ "github.com/cs161-staff/grades/policies/drops"
+	_ "github.com/cs161-staff/grades/policies/extensions"
+	_ "github.com/cs161-staff/grades/policies/latemultipliers"
+	_ "github.com/cs161-staff/grades/policies/overrides"
+	_ "github.com/cs161-staff/grades/policies/schemes"
+	_ "github.com/cs161-staff/grades/policies/slipdays"
+	_ "github.com/cs161-staff/grades/policies/weights"
+)
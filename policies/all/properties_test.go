@@ -0,0 +1,665 @@
+package all
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cs161-staff/grades"
+	"github.com/cs161-staff/grades/policies/clobber"
+	"github.com/cs161-staff/grades/policies/latemultipliers"
+	"github.com/cs161-staff/grades/policies/schemes"
+	"github.com/cs161-staff/grades/policies/weights"
+)
+
+var (
+	propertySeed   = flag.Int64("properties.seed", 1, "Seed for the policy property tests")
+	propertyRandom = flag.Bool("properties.random", false, "Use a random seed for the policy property tests instead of -properties.seed")
+	propertyCases  = flag.Int("properties.cases", 200, "Number of random cases per policy and property")
+)
+
+// propertyTolerance is the amount that a total score may decrease by due to
+// floating point error without violating a property.
+const propertyTolerance = 1e-9
+
+// basePipeline is the policies applied around the policy under test, so that
+// changes to lateness and drops affect the total score.
+var basePipeline = []string{"slipdays", "latemultipliers", "drops"}
+
+// testCase is a randomly generated course, roster and policy configuration.
+type testCase struct {
+	categories  map[string]*grades.Category
+	assignments map[string]*grades.Assignment
+	submissions map[int]map[string]grades.AssignmentSubmission
+
+	// sid is the student that properties are checked for.
+	sid int
+
+	// policy is the policy under test and params is its parameters as
+	// decoded JSON.
+	policy string
+	params interface{}
+
+	// scale is the late multiplier scale, which is non-increasing.
+	scale []float64
+}
+
+// clone returns a deep copy of the test case.
+func (c *testCase) clone() *testCase {
+	newCase := *c
+	newCase.categories = make(map[string]*grades.Category, len(c.categories))
+	for name, category := range c.categories {
+		newCase.categories[name] = category.Clone()
+	}
+	newCase.assignments = make(map[string]*grades.Assignment, len(c.assignments))
+	for name, assignment := range c.assignments {
+		newCase.assignments[name] = assignment.Clone()
+	}
+	newCase.submissions = make(map[int]map[string]grades.AssignmentSubmission, len(c.submissions))
+	for sid, submissions := range c.submissions {
+		newCase.submissions[sid] = make(map[string]grades.AssignmentSubmission, len(submissions))
+		for name, submission := range submissions {
+			newCase.submissions[sid][name] = submission
+		}
+	}
+	encoded, _ := json.Marshal(c.params)
+	json.Unmarshal(encoded, &newCase.params)
+	return &newCase
+}
+
+// twinSID is the SID of the copy of the student under test that a property's
+// change is applied to.
+func (c *testCase) twinSID() int {
+	return c.sid + 1000
+}
+
+// pipeline returns the base pipeline with the policy under test inserted as
+// early as it may come. If withPolicy is false, the policy under test is
+// omitted.
+func (c *testCase) pipeline(withPolicy bool) (grades.Pipeline, error) {
+	params, _ := json.Marshal(c.params)
+	lateParams, _ := json.Marshal(latemultipliers.Params{Scale: c.scale})
+	pipeline := make(grades.Pipeline, 0)
+	for _, name := range basePipeline {
+		if name == "latemultipliers" {
+			pipeline = append(pipeline, grades.Stage{Name: name, Params: lateParams})
+		} else {
+			pipeline = append(pipeline, grades.Stage{Name: name})
+		}
+	}
+	if withPolicy && c.params != nil {
+		return pipeline.Insert(grades.Stage{Name: c.policy, Params: c.twinParams(params)})
+	}
+	return pipeline, nil
+}
+
+// twinParams copies the parameters for the student under test to their twin,
+// for policies whose parameters are keyed by SID.
+func (c *testCase) twinParams(params json.RawMessage) json.RawMessage {
+	var bySID map[string]json.RawMessage
+	if err := json.Unmarshal(params, &bySID); err != nil {
+		return params
+	}
+	if studentParams, ok := bySID[strconv.Itoa(c.sid)]; ok {
+		bySID[strconv.Itoa(c.twinSID())] = studentParams
+	}
+	newParams, _ := json.Marshal(bySID)
+	return newParams
+}
+
+// run applies the pipeline to the roster and returns the total score of each
+// student. If change is not nil, a twin of the student under test is added to
+// the roster with the change applied. A panic is returned as an error.
+func (c *testCase) run(withPolicy bool, change func(student *grades.Student)) (totals map[int]float64, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	roster := make(grades.Roster, len(c.submissions)+1)
+	for sid, submissions := range c.submissions {
+		roster[sid] = []*grades.Student{grades.NewStudent(sid, strconv.Itoa(sid), c.categories, c.assignments, submissions)}
+	}
+	if change != nil {
+		twin := grades.NewStudent(c.twinSID(), "twin", c.categories, c.assignments, c.submissions[c.sid])
+		change(twin)
+		roster[twin.SID] = []*grades.Student{twin}
+	}
+
+	pipeline, err := c.pipeline(withPolicy)
+	if err != nil {
+		return nil, err
+	}
+	roster, err = pipeline.Apply(roster)
+	if err != nil {
+		return nil, err
+	}
+	totals = make(map[int]float64, len(roster))
+	for sid, report := range roster.Finalize() {
+		totals[sid] = report.TotalScore
+	}
+	return totals, nil
+}
+
+// String returns a description of the test case.
+func (c *testCase) String() string {
+	var builder strings.Builder
+	for _, name := range sortedNames(c.categories) {
+		category := c.categories[name]
+		fmt.Fprintf(&builder, "  category %s: weight %v, drops %d, slip days %d, late multiplier %t\n", name, category.Weight, category.Drops, category.SlipDays, category.HasLateMultiplier)
+	}
+	for _, name := range sortedNames(c.assignments) {
+		assignment := c.assignments[name]
+		fmt.Fprintf(&builder, "  assignment %s: category %s, max %v, weight %v, slip group %d\n", name, assignment.CategoryName, assignment.MaxScore, assignment.Weight, assignment.SlipGroup)
+	}
+	for _, sid := range c.sortedSIDs() {
+		fmt.Fprintf(&builder, "  student %d:", sid)
+		for _, name := range sortedNames(c.submissions[sid]) {
+			submission := c.submissions[sid][name]
+			fmt.Fprintf(&builder, " %s=%v", name, submission.Score)
+			if submission.Lateness != 0 {
+				fmt.Fprintf(&builder, "(late %v)", submission.Lateness)
+			}
+		}
+		builder.WriteString("\n")
+	}
+	fmt.Fprintf(&builder, "  late scale: %v", c.scale)
+	if c.params != nil {
+		params, _ := json.Marshal(c.params)
+		fmt.Fprintf(&builder, "\n  %s params: %s", c.policy, params)
+	}
+	return builder.String()
+}
+
+// sortedSIDs returns the SIDs of the students in the test case in sorted order.
+func (c *testCase) sortedSIDs() []int {
+	sids := make([]int, 0, len(c.submissions))
+	for sid := range c.submissions {
+		sids = append(sids, sid)
+	}
+	sort.Ints(sids)
+	return sids
+}
+
+// sortedNames returns the keys of the map in sorted order.
+func sortedNames(m interface{}) []string {
+	names := make([]string, 0)
+	switch m := m.(type) {
+	case map[string]*grades.Category:
+		for name := range m {
+			names = append(names, name)
+		}
+	case map[string]*grades.Assignment:
+		for name := range m {
+			names = append(names, name)
+		}
+	case map[string]grades.AssignmentSubmission:
+		for name := range m {
+			names = append(names, name)
+		}
+	case map[string]interface{}:
+		for name := range m {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// categoryAssignments returns the names of the assignments in the category in
+// sorted order.
+func (c *testCase) categoryAssignments(category string) []string {
+	names := make([]string, 0)
+	for _, name := range sortedNames(c.assignments) {
+		if c.assignments[name].CategoryName == category {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// generateCase generates a random test case for the policy.
+func generateCase(r *rand.Rand, policy string) *testCase {
+	c := &testCase{
+		categories:  make(map[string]*grades.Category),
+		assignments: make(map[string]*grades.Assignment),
+		submissions: make(map[int]map[string]grades.AssignmentSubmission),
+		sid:         1,
+		policy:      policy,
+		scale:       make([]float64, r.Intn(4)),
+	}
+
+	factor := 1.0
+	for i := range c.scale {
+		factor *= 0.5 + float64(r.Intn(6))/10.0
+		c.scale[i] = factor
+	}
+
+	slipGroup := 0
+	for i := 1 + r.Intn(3); i > 0; i-- {
+		category := &grades.Category{
+			Name:              fmt.Sprintf("C%d", len(c.categories)+1),
+			Weight:            float64(1+r.Intn(5)) / 10.0,
+			SlipDays:          r.Intn(3),
+			HasLateMultiplier: r.Intn(2) == 0,
+		}
+		c.categories[category.Name] = category
+		count := 1 + r.Intn(3)
+		for j := 0; j < count; j++ {
+			assignment := &grades.Assignment{
+				Name:         fmt.Sprintf("A%d", len(c.assignments)+1),
+				CategoryName: category.Name,
+				MaxScore:     []float64{1, 10, 100}[r.Intn(3)],
+				Weight:       float64(1 + r.Intn(3)),
+				SlipGroup:    -1,
+			}
+			if r.Intn(2) == 0 {
+				slipGroup++
+				assignment.SlipGroup = slipGroup
+			}
+			c.assignments[assignment.Name] = assignment
+		}
+		category.Drops = r.Intn(count)
+	}
+
+	for sid := 1; sid <= 2+r.Intn(3); sid++ {
+		c.submissions[sid] = make(map[string]grades.AssignmentSubmission)
+		for _, name := range sortedNames(c.assignments) {
+			assignment := c.assignments[name]
+			if r.Intn(10) == 0 {
+				continue
+			}
+			submission := grades.AssignmentSubmission{
+				Score: float64(r.Intn(int(assignment.MaxScore)*2+1)) / 2.0,
+			}
+			if r.Intn(3) == 0 {
+				submission.Lateness = time.Duration(r.Intn(120*60)) * time.Minute
+			}
+			c.submissions[sid][name] = submission
+		}
+	}
+
+	generator, ok := paramGenerators[policy]
+	if !ok {
+		return nil
+	}
+	if params := generator(r, c); params != nil {
+		encoded, _ := json.Marshal(params)
+		json.Unmarshal(encoded, &c.params)
+	}
+	return c
+}
+
+// paramGenerators generates random parameters for each policy. Policies
+// without parameters return nil. Every registered policy must have a
+// generator.
+var paramGenerators = map[string]func(r *rand.Rand, c *testCase) interface{}{
+	"addcomments": func(r *rand.Rand, c *testCase) interface{} {
+		return perStudent(r, c, sortedNames(c.assignments), func(name string) interface{} {
+			return []string{"Comment on " + name}
+		})
+	},
+	"changedrops": func(r *rand.Rand, c *testCase) interface{} {
+		return perStudent(r, c, sortedNames(c.categories), func(name string) interface{} {
+			drops := c.categories[name].Drops
+			return r.Intn(len(c.categoryAssignments(name))+1) - drops
+		})
+	},
+	"changeslipdays": func(r *rand.Rand, c *testCase) interface{} {
+		return perStudent(r, c, sortedNames(c.categories), func(name string) interface{} {
+			return r.Intn(4) - c.categories[name].SlipDays
+		})
+	},
+	"clobber": func(r *rand.Rand, c *testCase) interface{} {
+		names := sortedNames(c.assignments)
+		source := names[r.Intn(len(names))]
+		target := names[r.Intn(len(names))]
+		return clobber.Params{
+			Source: source,
+			Target: target,
+			Style:  []string{"scaled", "zscore"}[r.Intn(2)],
+		}
+	},
+	"drops": func(r *rand.Rand, c *testCase) interface{} {
+		return nil
+	},
+	"extensions": func(r *rand.Rand, c *testCase) interface{} {
+		return perStudent(r, c, sortedNames(c.assignments), func(name string) interface{} {
+			if r.Intn(2) == 0 {
+				return fmt.Sprintf("%dh", r.Intn(72))
+			}
+			return r.Intn(4)
+		})
+	},
+	"latemultipliers": func(r *rand.Rand, c *testCase) interface{} {
+		return nil
+	},
+	"overrides": func(r *rand.Rand, c *testCase) interface{} {
+		return perStudent(r, c, sortedNames(c.assignments), func(name string) interface{} {
+			return float64(r.Intn(int(c.assignments[name].MaxScore) + 1))
+		})
+	},
+	"schemes": func(r *rand.Rand, c *testCase) interface{} {
+		params := schemes.Params{}
+		for i := r.Intn(3); i >= 0; i-- {
+			scheme := &schemes.Scheme{Name: strconv.Itoa(i)}
+			scheme.Categories = make(map[string]float64)
+			for _, name := range sortedNames(c.categories) {
+				scheme.Categories[name] = float64(r.Intn(5)) / 10.0
+			}
+			params.Schemes = append(params.Schemes, scheme)
+		}
+		return params
+	},
+	"slipdays": func(r *rand.Rand, c *testCase) interface{} {
+		return nil
+	},
+	"weights": func(r *rand.Rand, c *testCase) interface{} {
+		categoryNames := sortedNames(c.categories)
+		params := make(map[int]*weights.Params)
+		for _, sid := range c.sortedSIDs() {
+			if r.Intn(2) == 0 {
+				continue
+			}
+			studentParams := &weights.Params{
+				Categories:  make(map[string]float64),
+				Assignments: make(map[string]float64),
+			}
+			for _, name := range categoryNames {
+				if r.Intn(2) == 0 {
+					studentParams.Categories[name] = float64(r.Intn(5)) / 10.0
+				}
+			}
+			for _, name := range sortedNames(c.assignments) {
+				if r.Intn(2) == 0 {
+					studentParams.Assignments[name] = float64(r.Intn(3))
+				}
+			}
+			if len(categoryNames) > 1 && r.Intn(2) == 0 {
+				from := r.Intn(len(categoryNames))
+				to := (from + 1 + r.Intn(len(categoryNames)-1)) % len(categoryNames)
+				studentParams.Transfers = append(studentParams.Transfers, weights.Transfer{From: categoryNames[from], To: categoryNames[to]})
+			}
+			params[sid] = studentParams
+		}
+		return params
+	},
+}
+
+// perStudent returns SID -> name -> value parameters for a random subset of
+// the students and names.
+func perStudent(r *rand.Rand, c *testCase, names []string, value func(name string) interface{}) map[int]map[string]interface{} {
+	params := make(map[int]map[string]interface{})
+	for _, sid := range c.sortedSIDs() {
+		if r.Intn(2) == 0 {
+			continue
+		}
+		params[sid] = make(map[string]interface{})
+		for _, name := range names {
+			if r.Intn(2) == 0 {
+				params[sid][name] = value(name)
+			}
+		}
+	}
+	return params
+}
+
+// property is an invariant that a policy must satisfy. A property makes a
+// change that must not lower the student's total score: either a change to a
+// twin of the student, or adding the policy under test to the pipeline.
+type property struct {
+	name string
+
+	// applies returns whether the property applies to the policy.
+	applies func(policy string) bool
+
+	// change returns a description of a random change to the student under
+	// test and a function that applies it. If change is nil, the change is
+	// adding the policy to the pipeline.
+	change func(r *rand.Rand, c *testCase) (string, func(student *grades.Student))
+}
+
+// randomSubmission returns the name of a random assignment that the student
+// under test submitted, or "" if there is none.
+func randomSubmission(r *rand.Rand, c *testCase) string {
+	names := sortedNames(c.submissions[c.sid])
+	if len(names) == 0 {
+		return ""
+	}
+	return names[r.Intn(len(names))]
+}
+
+var properties = []property{
+	{
+		name:    "raising a raw score never lowers the total",
+		applies: func(policy string) bool { return true },
+		change: func(r *rand.Rand, c *testCase) (string, func(student *grades.Student)) {
+			name := randomSubmission(r, c)
+			increase := float64(1+r.Intn(20)) / 2.0
+			return fmt.Sprintf("raise %s by %v", name, increase), func(student *grades.Student) {
+				if assignment, ok := student.Assignments[name]; ok {
+					assignment.Grade.Score = math.Min(assignment.Grade.Score+increase, assignment.MaxScore)
+				}
+			}
+		},
+	},
+	{
+		name:    "adding a drop never lowers the best outcome",
+		applies: func(policy string) bool { return true },
+		change: func(r *rand.Rand, c *testCase) (string, func(student *grades.Student)) {
+			names := sortedNames(c.categories)
+			name := names[r.Intn(len(names))]
+			return fmt.Sprintf("add a drop to %s", name), func(student *grades.Student) {
+				if category, ok := student.Categories[name]; ok {
+					category.Drops++
+				}
+			}
+		},
+	},
+	{
+		name:    "extending a deadline never hurts",
+		applies: func(policy string) bool { return true },
+		change: func(r *rand.Rand, c *testCase) (string, func(student *grades.Student)) {
+			name := randomSubmission(r, c)
+			extension := time.Duration(1+r.Intn(72)) * time.Hour
+			return fmt.Sprintf("extend %s by %v", name, extension), func(student *grades.Student) {
+				if assignment, ok := student.Assignments[name]; ok {
+					assignment.Grade.Lateness -= extension
+				}
+			}
+		},
+	},
+	{
+		name: "applying the policy never lowers the total",
+		applies: func(policy string) bool {
+			return policy == "clobber" || policy == "extensions"
+		},
+	},
+}
+
+// check checks the property on the test case, returning a description of the
+// violation or nil.
+func check(c *testCase, p property, description string, change func(student *grades.Student)) error {
+	var before, after float64
+	if change == nil {
+		without, err := c.run(false, nil)
+		if err != nil {
+			return err
+		}
+		with, err := c.run(true, nil)
+		if err != nil {
+			return err
+		}
+		before, after = without[c.sid], with[c.sid]
+		description = "apply " + c.policy
+	} else {
+		totals, err := c.run(true, change)
+		if err != nil {
+			return err
+		}
+		before, after = totals[c.sid], totals[c.twinSID()]
+	}
+	if math.IsNaN(before) || math.IsNaN(after) || after < before-propertyTolerance {
+		return fmt.Errorf("%s: total went from %v to %v", description, before, after)
+	}
+	return nil
+}
+
+// shrinkCandidates returns simpler variations of the test case, in an order
+// that depends only on the test case so that shrinking is deterministic.
+func shrinkCandidates(c *testCase) []*testCase {
+	candidates := make([]*testCase, 0)
+
+	// Remove other students.
+	for _, sid := range c.sortedSIDs() {
+		if sid != c.sid {
+			candidate := c.clone()
+			delete(candidate.submissions, sid)
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	// Remove assignments and empty categories.
+	for _, name := range sortedNames(c.assignments) {
+		candidate := c.clone()
+		delete(candidate.assignments, name)
+		for _, submissions := range candidate.submissions {
+			delete(submissions, name)
+		}
+		candidates = append(candidates, candidate)
+	}
+	for _, name := range sortedNames(c.categories) {
+		if len(c.categoryAssignments(name)) == 0 && len(c.categories) > 1 {
+			candidate := c.clone()
+			delete(candidate.categories, name)
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	// Simplify categories.
+	for _, name := range sortedNames(c.categories) {
+		if category := c.categories[name]; category.Drops > 0 || category.SlipDays > 0 {
+			candidate := c.clone()
+			candidate.categories[name].Drops = 0
+			candidate.categories[name].SlipDays = 0
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	// Simplify submissions.
+	for _, sid := range c.sortedSIDs() {
+		for _, name := range sortedNames(c.submissions[sid]) {
+			submission := c.submissions[sid][name]
+			if submission.Lateness != 0 {
+				candidate := c.clone()
+				submission.Lateness = 0
+				candidate.submissions[sid][name] = submission
+				candidates = append(candidates, candidate)
+			}
+			if submission.Score != 0 && submission.Score != c.assignments[name].MaxScore {
+				candidate := c.clone()
+				submission.Score = c.assignments[name].MaxScore
+				candidate.submissions[sid][name] = submission
+				candidates = append(candidates, candidate)
+			}
+		}
+	}
+
+	// Remove parameters of policies keyed by SID.
+	if bySID, ok := c.params.(map[string]interface{}); ok {
+		for _, key := range sortedNames(bySID) {
+			if _, err := strconv.Atoi(key); err != nil {
+				continue
+			}
+			candidate := c.clone()
+			delete(candidate.params.(map[string]interface{}), key)
+			candidates = append(candidates, candidate)
+			if inner, ok := bySID[key].(map[string]interface{}); ok {
+				for _, name := range sortedNames(inner) {
+					candidate := c.clone()
+					delete(candidate.params.(map[string]interface{})[key].(map[string]interface{}), name)
+					candidates = append(candidates, candidate)
+				}
+			}
+		}
+	}
+
+	return candidates
+}
+
+// failureKind classifies a failure as a panic, invalid parameters or a
+// violated property, so that shrinking does not turn one into another, such as
+// by removing an assignment named in the parameters.
+func failureKind(err error) string {
+	switch {
+	case strings.HasPrefix(err.Error(), "panic:"):
+		return "panic"
+	case strings.HasPrefix(err.Error(), "Invalid parameters"):
+		return "invalid"
+	default:
+		return "violation"
+	}
+}
+
+// shrink repeatedly replaces the failing test case with a simpler variation
+// that fails in the same way, until there is none.
+func shrink(c *testCase, p property, description string, change func(student *grades.Student), failure error) (*testCase, error) {
+	kind := failureKind(failure)
+	for shrunk := true; shrunk; {
+		shrunk = false
+		candidates := shrinkCandidates(c)
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return len(candidates[i].String()) < len(candidates[j].String())
+		})
+		for _, candidate := range candidates {
+			err := check(candidate, p, description, change)
+			if err != nil && failureKind(err) == kind {
+				c, failure = candidate, err
+				shrunk = true
+				break
+			}
+		}
+	}
+	return c, failure
+}
+
+func TestPolicyProperties(t *testing.T) {
+	seed := *propertySeed
+	if *propertyRandom {
+		seed = time.Now().UnixNano()
+	}
+	r := rand.New(rand.NewSource(seed))
+
+	for _, policy := range grades.RegisteredPolicies() {
+		if _, ok := paramGenerators[policy]; !ok {
+			t.Errorf("No parameter generator for policy %s", policy)
+			continue
+		}
+		for _, p := range properties {
+			if !p.applies(policy) {
+				continue
+			}
+			for i := 0; i < *propertyCases; i++ {
+				c := generateCase(r, policy)
+				var description string
+				var change func(student *grades.Student)
+				if p.change != nil {
+					description, change = p.change(r, c)
+				}
+				if err := check(c, p, description, change); err != nil {
+					c, err = shrink(c, p, description, change, err)
+					t.Errorf("Policy %s violates %q (seed %d)\n  %v\n%v", policy, p.name, seed, err, c)
+					break
+				}
+			}
+		}
+	}
+}
@@ -1,9 +1,21 @@
 package changedrops
 
 import (
+	"encoding/json"
+
 	"github.com/cs161-staff/grades"
 )
 
+func init() {
+	grades.RegisterPolicy("changedrops", func(rawParams json.RawMessage, roster grades.Roster) (grades.Policy, error) {
+		var params map[int]map[string]int
+		if err := json.Unmarshal(rawParams, &params); err != nil {
+			return nil, err
+		}
+		return Make(params), nil
+	})
+}
+
 // Make takes in a student ID -> category name -> drop adjust map and returns a
 // policy that adjusts the specified categories for the specified students,
 // adding the adjustment to the number of drops, returning it as the only new
@@ -1,9 +1,21 @@
 package changeslipdays
 
 import (
+	"encoding/json"
+
 	"github.com/cs161-staff/grades"
 )
 
+func init() {
+	grades.RegisterPolicy("changeslipdays", func(rawParams json.RawMessage, roster grades.Roster) (grades.Policy, error) {
+		var params map[int]map[string]int
+		if err := json.Unmarshal(rawParams, &params); err != nil {
+			return nil, err
+		}
+		return Make(params), nil
+	})
+}
+
 // Make takes in a student ID -> category name -> slip day adjust map and
 // returns a policy that adjusts the specified categories for the specified
 // students, adding the adjustment to the number of slip days, returning it as
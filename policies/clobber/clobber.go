@@ -1,8 +1,10 @@
 package clobber
 
 import (
+	"encoding/json"
 	"errors"
 	"math"
+	"sort"
 
 	"github.com/cs161-staff/grades"
 )
@@ -14,6 +16,48 @@ const (
 	StyleZScore
 )
 
+// Params is the configuration parameters of the clobber policy.
+type Params struct {
+	// Source is the name of the assignment clobbered from.
+	Source string `json:"source"`
+
+	// Target is the name of the assignment clobbered to.
+	Target string `json:"target"`
+
+	// Style is the clobber style, either "scaled" (the default) or "zscore".
+	Style string `json:"style,omitempty"`
+}
+
+func init() {
+	grades.RegisterRosterPolicy("clobber", func(rawParams json.RawMessage, roster grades.Roster) (grades.Policy, error) {
+		var params Params
+		if err := json.Unmarshal(rawParams, &params); err != nil {
+			return nil, err
+		}
+		var style ClobberStyle
+		switch params.Style {
+		case "scaled", "":
+			style = StyleScaled
+		case "zscore":
+			style = StyleZScore
+		default:
+			return nil, errors.New("Invalid clobber style " + params.Style)
+		}
+		// Sort the students so that their statistics are summed in the same
+		// order from run to run.
+		sids := make([]int, 0, len(roster))
+		for sid := range roster {
+			sids = append(sids, sid)
+		}
+		sort.Ints(sids)
+		students := make([]*grades.Student, 0, len(roster))
+		for _, sid := range sids {
+			students = append(students, roster[sid][0])
+		}
+		return Make(params.Source, params.Target, style, students), nil
+	}, "extensions", "slipdays", "latemultipliers", "overrides")
+}
+
 // Make returns a policy that clobbers from the source assignment name to the
 // target assignment name according to the given clobber type. The
 // possibiltiies are always either applying the clobber or not applying the
@@ -33,9 +77,9 @@ func Make(source string, target string, style ClobberStyle, students []*grades.S
 		}
 	case StyleZScore:
 		// Generate grade reports for students.
-		reports := make(map[int]*grades.GradeReport)
-		for id, student := range students {
-			reports[id] = student.GenerateGradeReport()
+		reports := make([]*grades.GradeReport, len(students))
+		for i, student := range students {
+			reports[i] = student.GenerateGradeReport()
 		}
 
 		// Compute source and target mean.
@@ -1,25 +1,48 @@
 package drops
 
 import (
+	"encoding/json"
+
 	"github.com/cs161-staff/grades"
 )
 
+func init() {
+	grades.RegisterPolicy("drops", func(params json.RawMessage, roster grades.Roster) (grades.Policy, error) {
+		return Apply, nil
+	}, "extensions", "changedrops", "slipdays", "latemultipliers", "overrides", "clobber", "weights", "schemes")
+}
+
 // Apply applies a drop policy by returning all possible combinations of
 // dropping assignments as possibilities, based on the number of drops in each
-// category.
+// category. Every category keeps at least one assignment, even if it has as
+// many drops as assignments.
 var Apply grades.Policy = apply
 
 func apply(student *grades.Student) []*grades.Student {
 	// Get combinations of assignments in each category.
-	categoryCombos := make([][][]*grades.Assignment, len(student.Categories))
-	for _, category := range student.Categories {
+	categoryCombos := make([][][]*grades.Assignment, 0, len(student.Categories))
+	assignmentNames := student.AssignmentNames()
+	for _, categoryName := range student.CategoryNames() {
+		category := student.Categories[categoryName]
 		assignmentsInCategory := make([]*grades.Assignment, 0)
-		for _, assignment := range student.Assignments {
-			if assignment.CategoryName == category.Name {
+		for _, name := range assignmentNames {
+			assignment := student.Assignments[name]
+			// Pending assignments have no score yet, so dropping them would
+			// not change the category's score.
+			if assignment.CategoryName == category.Name && assignment.Grade.Status != grades.StatusPending {
 				assignmentsInCategory = append(assignmentsInCategory, assignment)
 			}
 		}
-		categoryCombos = append(categoryCombos, combinations(assignmentsInCategory, category.Drops))
+		// Always keep at least one assignment, since dropping every
+		// assignment would leave the category with a score of 0.
+		drops := category.Drops
+		if drops > len(assignmentsInCategory)-1 {
+			drops = len(assignmentsInCategory) - 1
+		}
+		if drops < 0 {
+			drops = 0
+		}
+		categoryCombos = append(categoryCombos, combinations(assignmentsInCategory, drops))
 	}
 
 	// Get cross product of all category combos.
@@ -65,7 +88,7 @@ func combinations(elems []*grades.Assignment, n int) [][]*grades.Assignment {
 // Returns the cross product of the given slices.
 func crossProduct(slices ...[][]*grades.Assignment) [][][]*grades.Assignment {
 	if len(slices) == 0 {
-		return [][][]*grades.Assignment{}
+		return [][][]*grades.Assignment{{}}
 	}
 
 	// Get length of the cross product so that allocation can be done at once.
@@ -7,6 +7,52 @@ import (
 	"github.com/cs161-staff/grades"
 )
 
+func TestApplyWithoutCategories(t *testing.T) {
+	if outcomes := apply(&grades.Student{}); len(outcomes) != 1 {
+		t.Errorf("Got %d outcomes for a student without categories; expected 1", len(outcomes))
+	}
+}
+
+func TestApplyMoreDropsThanAssignments(t *testing.T) {
+	student := &grades.Student{
+		Categories: map[string]*grades.Category{
+			"Homework": {Name: "Homework", Drops: 3},
+		},
+		Assignments: map[string]*grades.Assignment{
+			"HW 1": {Name: "HW 1", CategoryName: "Homework"},
+		},
+	}
+	if outcomes := apply(student); len(outcomes) != 1 {
+		t.Errorf("Got %d outcomes for a category with more drops than assignments; expected 1", len(outcomes))
+	}
+}
+
+func TestApplyKeepsOneAssignment(t *testing.T) {
+	categories := map[string]*grades.Category{
+		"Homework": {Name: "Homework", Drops: 3},
+	}
+	assignments := map[string]*grades.Assignment{
+		"HW 1": {Name: "HW 1", CategoryName: "Homework", MaxScore: 1, Weight: 1},
+		"HW 2": {Name: "HW 2", CategoryName: "Homework", MaxScore: 1, Weight: 1},
+	}
+	student := grades.NewStudent(1, "Student", categories, assignments, nil)
+	outcomes := apply(student)
+	if len(outcomes) != 2 {
+		t.Fatalf("Got %d outcomes; expected 2", len(outcomes))
+	}
+	for i, outcome := range outcomes {
+		kept := 0
+		for _, assignment := range outcome.Assignments {
+			if !assignment.Grade.Dropped {
+				kept++
+			}
+		}
+		if kept != 1 {
+			t.Errorf("Outcome %d kept %d assignments; expected 1", i, kept)
+		}
+	}
+}
+
 func TestCombinations(t *testing.T) {
 	elems := []*grades.Assignment{
 		{SlipGroup: 1},
@@ -32,3 +78,36 @@ func TestCombinations(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestApplyOrder(t *testing.T) {
+	categories := map[string]*grades.Category{
+		"Homework": {Name: "Homework", Drops: 1},
+		"Projects": {Name: "Projects", Drops: 1},
+	}
+	assignments := make(map[string]*grades.Assignment)
+	for _, name := range []string{"HW 1", "HW 2", "HW 3", "HW 4"} {
+		assignments[name] = &grades.Assignment{Name: name, CategoryName: "Homework", MaxScore: 1, Weight: 1}
+	}
+	for _, name := range []string{"Proj 1", "Proj 2", "Proj 3"} {
+		assignments[name] = &grades.Assignment{Name: name, CategoryName: "Projects", MaxScore: 1, Weight: 1}
+	}
+	student := grades.NewStudent(1, "Student", categories, assignments, nil)
+
+	dropped := func() []string {
+		names := make([]string, 0)
+		for _, outcome := range apply(student) {
+			for _, name := range outcome.AssignmentNames() {
+				if outcome.Assignments[name].Grade.Dropped {
+					names = append(names, name)
+				}
+			}
+		}
+		return names
+	}
+	expected := dropped()
+	for i := 0; i < 10; i++ {
+		if names := dropped(); !reflect.DeepEqual(names, expected) {
+			t.Fatalf("Outcomes in different order between runs: %v and %v", expected, names)
+		}
+	}
+}
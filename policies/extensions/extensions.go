@@ -1,25 +1,175 @@
 package extensions
 
 import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cs161-staff/grades"
 )
 
-// Make takes in a student ID -> assignment name -> extension days map and
-// returns a policy that subtracts the specified number of days from the
-// assignments from the specified students, returning it as the only new
-// outcome for the student.
-func Make(extensions map[int]map[string]int) grades.Policy {
+// Extension is an extension on an assignment's deadline. Only one of its
+// fields is set.
+type Extension struct {
+	// Length is the length of the extension.
+	Length time.Duration
+
+	// Percent is the extension as a percentage of the assignment's window,
+	// from its release to its deadline.
+	Percent float64
+
+	// Deadline is the assignment's new deadline.
+	Deadline time.Time
+}
+
+// ParseExtension parses an extension written as a number of days like "2", a
+// duration like "36h", a percentage of the assignment's window like "50%" or
+// a deadline in any format that grades.ParseTime accepts, like
+// "2021-10-01T17:00:00-07:00". Negative extensions are rejected.
+func ParseExtension(value string) (Extension, error) {
+	value = strings.TrimSpace(value)
+	if days, err := strconv.ParseFloat(value, 64); err == nil {
+		return daysExtension(days)
+	}
+	if strings.HasSuffix(value, "%") {
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		if err != nil {
+			return Extension{}, fmt.Errorf("Invalid percentage extension %q: %w", value, err)
+		}
+		if percent < 0.0 {
+			return Extension{}, fmt.Errorf("Negative extension %q", value)
+		}
+		return Extension{Percent: percent}, nil
+	}
+	if length, err := time.ParseDuration(value); err == nil {
+		if length < 0 {
+			return Extension{}, fmt.Errorf("Negative extension %q", value)
+		}
+		return Extension{Length: length}, nil
+	}
+	if deadline, err := grades.ParseTime(value); err == nil {
+		return Extension{Deadline: deadline}, nil
+	}
+	return Extension{}, fmt.Errorf("Extension %q is not a number of days, a duration, a percentage or a deadline", value)
+}
+
+// daysExtension returns an extension of the given number of days, which must
+// not be negative.
+func daysExtension(days float64) (Extension, error) {
+	if days < 0.0 {
+		return Extension{}, fmt.Errorf("Negative extension of %v days", days)
+	}
+	return Extension{Length: time.Duration(days * float64(24*time.Hour))}, nil
+}
+
+// Validate checks that the assignment has the due time needed for a new
+// deadline, or the release and due times needed for a percentage.
+func (extension Extension) Validate(assignment *grades.Assignment) error {
+	switch {
+	case !extension.Deadline.IsZero() && assignment.Due.IsZero():
+		return fmt.Errorf("Extension to a new deadline on assignment %s, which has no due time", assignment.Name)
+	case !extension.Deadline.IsZero() && extension.Deadline.Before(assignment.Due):
+		return fmt.Errorf("Extension to %s on assignment %s is before its due time", extension, assignment.Name)
+	case extension.Percent != 0.0 && assignment.Window() == 0:
+		return fmt.Errorf("Percentage extension on assignment %s, which has no release and due times", assignment.Name)
+	}
+	return nil
+}
+
+// Duration returns how much later the extension makes the assignment due.
+func (extension Extension) Duration(assignment *grades.Assignment) time.Duration {
+	if !extension.Deadline.IsZero() {
+		return extension.Deadline.Sub(assignment.Due)
+	}
+	return extension.Length + time.Duration(float64(assignment.Window())*extension.Percent/100.0)
+}
+
+// String returns the extension as ParseExtension parses it, using a number of
+// days for lengths that are whole days.
+func (extension Extension) String() string {
+	switch {
+	case !extension.Deadline.IsZero():
+		return extension.Deadline.Format(time.RFC3339)
+	case extension.Percent != 0.0:
+		return strconv.FormatFloat(extension.Percent, 'f', -1, 64) + "%"
+	case extension.Length%(24*time.Hour) == 0:
+		return strconv.Itoa(int(extension.Length / (24 * time.Hour)))
+	}
+	return extension.Length.String()
+}
+
+// MarshalJSON encodes an extension of whole days as a number and any other
+// extension as a string.
+func (extension Extension) MarshalJSON() ([]byte, error) {
+	if extension.Deadline.IsZero() && extension.Percent == 0.0 && extension.Length%(24*time.Hour) == 0 {
+		return json.Marshal(int(extension.Length / (24 * time.Hour)))
+	}
+	return json.Marshal(extension.String())
+}
+
+// UnmarshalJSON decodes a number of days or a string in any format that
+// ParseExtension accepts.
+func (extension *Extension) UnmarshalJSON(data []byte) error {
+	var days float64
+	if err := json.Unmarshal(data, &days); err == nil {
+		parsed, err := daysExtension(days)
+		if err != nil {
+			return err
+		}
+		*extension = parsed
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("Extension %s is not a number of days or a string", data)
+	}
+	parsed, err := ParseExtension(value)
+	if err != nil {
+		return err
+	}
+	*extension = parsed
+	return nil
+}
+
+func init() {
+	grades.RegisterPolicy("extensions", func(rawParams json.RawMessage, roster grades.Roster) (grades.Policy, error) {
+		var params map[int]map[string]Extension
+		if err := json.Unmarshal(rawParams, &params); err != nil {
+			return nil, err
+		}
+		for sid, studentExtensions := range params {
+			for _, student := range roster[sid] {
+				for assignmentName, extension := range studentExtensions {
+					assignment, ok := student.Assignments[assignmentName]
+					if !ok {
+						return nil, fmt.Errorf("Extension for SID %d on unknown assignment %s", sid, assignmentName)
+					}
+					if err := extension.Validate(assignment); err != nil {
+						return nil, fmt.Errorf("Invalid extension for SID %d: %w", sid, err)
+					}
+				}
+			}
+		}
+		return Make(params), nil
+	})
+}
+
+// Make takes in a student ID -> assignment name -> extension map and returns a
+// policy that subtracts how much later each extension makes the assignment due
+// from the lateness of the assignments from the specified students, returning
+// it as the only new outcome for the student.
+func Make(extensions map[int]map[string]Extension) grades.Policy {
 	return func(student *grades.Student) []*grades.Student {
 		studentExtensions, ok := extensions[student.SID]
 		if !ok {
 			return []*grades.Student{student}
 		}
 		newStudent := student.CloneWithAssignments()
-		for assignmentName, extensionDays := range studentExtensions {
+		for assignmentName, extension := range studentExtensions {
 			newAssignment := newStudent.Assignments[assignmentName].Clone()
-			newAssignment.Grade.Lateness -= time.Hour * 24 * time.Duration(extensionDays)
+			newAssignment.Grade.Lateness -= extension.Duration(newAssignment)
 			newStudent.Assignments[assignmentName] = newAssignment
 		}
 		return []*grades.Student{newStudent}
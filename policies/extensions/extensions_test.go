@@ -0,0 +1,89 @@
+package extensions
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/cs161-staff/grades"
+)
+
+func TestMake(t *testing.T) {
+	released := time.Date(2021, 9, 1, 17, 0, 0, 0, time.UTC)
+	student := &grades.Student{
+		SID: 1,
+		Assignments: map[string]*grades.Assignment{
+			"HW 1": {Name: "HW 1", Released: released, Due: released.Add(48 * time.Hour), Grade: grades.AssignmentSubmission{Lateness: 30 * time.Hour}},
+			"HW 2": {Name: "HW 2", Grade: grades.AssignmentSubmission{Lateness: 30 * time.Hour}},
+		},
+	}
+	var params map[int]map[string]Extension
+	if err := json.Unmarshal([]byte(`{"1": {"HW 1": "50%", "HW 2": "24h"}}`), &params); err != nil {
+		t.Fatal(err)
+	}
+	outcomes := Make(params)(student)
+	if len(outcomes) != 1 {
+		t.Fatalf("Got %d outcomes; expected 1", len(outcomes))
+	}
+	if lateness := outcomes[0].Assignments["HW 1"].Grade.Lateness; lateness != 6*time.Hour {
+		t.Errorf("Got lateness %v for a 50%% extension; expected 6h", lateness)
+	}
+	if lateness := outcomes[0].Assignments["HW 2"].Grade.Lateness; lateness != 6*time.Hour {
+		t.Errorf("Got lateness %v for a 1 day extension; expected 6h", lateness)
+	}
+	if student.Assignments["HW 1"].Grade.Lateness != 30*time.Hour {
+		t.Error("Original student was changed")
+	}
+
+	encoded, err := json.Marshal(params[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != `{"HW 1":"50%","HW 2":1}` {
+		t.Errorf("Got encoded extensions %s", encoded)
+	}
+	if err := json.Unmarshal([]byte(`{"1": {"HW 1": "two days"}}`), &params); err == nil {
+		t.Error("Invalid extension accepted")
+	}
+
+	deadline, err := ParseExtension("2021-09-04T17:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := deadline.Validate(student.Assignments["HW 2"]); err == nil {
+		t.Error("New deadline accepted for an assignment without a due time")
+	}
+	if duration := deadline.Duration(student.Assignments["HW 1"]); duration != 24*time.Hour {
+		t.Errorf("Got duration %v for a new deadline; expected 24h", duration)
+	}
+}
+
+func TestParseExtension(t *testing.T) {
+	deadline := time.Date(2021, 10, 1, 17, 0, 0, 0, time.FixedZone("", -7*60*60))
+	for value, expected := range map[string]Extension{
+		"2":                          {Length: 48 * time.Hour},
+		"0.5":                        {Length: 12 * time.Hour},
+		"36h":                        {Length: 36 * time.Hour},
+		"50%":                        {Percent: 50},
+		"2021-10-01T17:00:00-07:00":  {Deadline: deadline},
+		" 2021-10-01 17:00:00 -0700": {Deadline: deadline},
+	} {
+		extension, err := ParseExtension(value)
+		if err != nil {
+			t.Errorf("ParseExtension(%q) failed: %v", value, err)
+			continue
+		}
+		if extension.Length != expected.Length || extension.Percent != expected.Percent || !extension.Deadline.Equal(expected.Deadline) {
+			t.Errorf("ParseExtension(%q) = %+v; expected %+v", value, extension, expected)
+		}
+	}
+	for _, value := range []string{"", "-1", "-36h", "-50%", "x%", "two days", "10/01/2021"} {
+		if extension, err := ParseExtension(value); err == nil {
+			t.Errorf("ParseExtension(%q) = %+v; expected an error", value, extension)
+		}
+	}
+	var extension Extension
+	if err := json.Unmarshal([]byte(`-1`), &extension); err == nil {
+		t.Error("Negative number of days accepted in JSON")
+	}
+}
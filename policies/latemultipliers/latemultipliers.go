@@ -1,6 +1,7 @@
 package latemultipliers
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/cs161-staff/grades"
@@ -8,17 +9,48 @@ import (
 
 const MultiplierDesc = "Late multipier"
 
+// Params is the configuration parameters of the late multiplier policy.
+type Params struct {
+	// Scale is the sliding scale of multipliers passed to Make.
+	Scale []float64 `json:"scale"`
+
+	// Grace is the grace period passed to Make, as a duration string such as
+	// "15m". It may be empty for no grace period.
+	Grace string `json:"grace,omitempty"`
+}
+
+func init() {
+	grades.RegisterPolicy("latemultipliers", func(rawParams json.RawMessage, roster grades.Roster) (grades.Policy, error) {
+		var params Params
+		if err := json.Unmarshal(rawParams, &params); err != nil {
+			return nil, err
+		}
+		var grace time.Duration
+		if params.Grace != "" {
+			var err error
+			grace, err = time.ParseDuration(params.Grace)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return Make(params.Scale, grace), nil
+	}, "extensions", "slipdays")
+}
+
 // Make constructs a late multiplier policy based on a sliding scale based on
 // the number of days late. If an assignment is n days late, the late
-// multiplier is scale[n - 1]. If an assignment is past len(scale) + 1 days
+// multiplier is scale[n - 1]. If an assignment is more than len(scale) days
 // late, a x0 multiplier is applied. If an assignment is not late, no
-// multiplier is applied.
+// multiplier is applied. Assignments without a slip group are judged by their
+// own lateness, and the others by the lateness of their slip group.
 func Make(scale []float64, grace time.Duration) grades.Policy {
 	return func(student *grades.Student) []*grades.Student {
 		// Get a map of the lateness of all slip groups. The lateness of a
 		// group is the maximum lateness of any assignment in the group.
+		assignmentNames := student.AssignmentNames()
 		groupLatenesses := make(map[int]time.Duration)
-		for _, assignment := range student.Assignments {
+		for _, name := range assignmentNames {
+			assignment := student.Assignments[name]
 			if curLateness, ok := groupLatenesses[assignment.SlipGroup]; !ok || curLateness < assignment.Grade.Lateness {
 				groupLatenesses[assignment.SlipGroup] = assignment.Grade.Lateness
 			}
@@ -26,13 +58,14 @@ func Make(scale []float64, grace time.Duration) grades.Policy {
 
 		// Apply lateness multipliers based on the lateness of the groups.
 		newStudent := student.CloneWithAssignments()
-		for _, assignment := range student.Assignments {
+		for _, name := range assignmentNames {
+			assignment := student.Assignments[name]
 			category := student.Categories[assignment.CategoryName]
 
 			// Lateness is based on individual assignment if no slip group,
 			// else use the slip groups value.
 			var lateness time.Duration
-			if assignment.SlipGroup == 0 {
+			if assignment.SlipGroup < 0 {
 				lateness = assignment.Grade.Lateness
 			} else {
 				lateness = groupLatenesses[assignment.SlipGroup]
@@ -61,7 +94,7 @@ func Make(scale []float64, grace time.Duration) grades.Policy {
 
 			// Apply late multiplier based on scale.
 			var multiplier grades.Multiplier
-			if daysLate-1 > len(curScale) {
+			if daysLate > len(curScale) {
 				// Too late; x0 multipiler.
 				multiplier.Factor = 0.0
 			} else {
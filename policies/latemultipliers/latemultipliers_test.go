@@ -0,0 +1,49 @@
+package latemultipliers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cs161-staff/grades"
+)
+
+func TestMake(t *testing.T) {
+	student := &grades.Student{
+		Categories: map[string]*grades.Category{
+			"Homework": {Name: "Homework", HasLateMultiplier: true},
+		},
+		Assignments: map[string]*grades.Assignment{
+			"HW 1": {Name: "HW 1", CategoryName: "Homework", SlipGroup: -1, Grade: grades.AssignmentSubmission{Lateness: 30 * time.Hour}},
+			"HW 2": {Name: "HW 2", CategoryName: "Homework", SlipGroup: -1, Grade: grades.AssignmentSubmission{Lateness: 80 * time.Hour}},
+			"HW 3": {Name: "HW 3", CategoryName: "Homework", SlipGroup: -1},
+			"HW 4": {Name: "HW 4", CategoryName: "Homework", SlipGroup: 0, Grade: grades.AssignmentSubmission{Lateness: 2 * time.Hour}},
+			"HW 5": {Name: "HW 5", CategoryName: "Homework", SlipGroup: 0},
+		},
+	}
+	outcomes := Make([]float64{0.9, 0.8}, 0)(student)
+	if len(outcomes) != 1 {
+		t.Fatalf("Got %d outcomes; expected 1", len(outcomes))
+	}
+	// Assignments without a slip group are judged by their own lateness, an
+	// assignment more days late than the scale covers gets x0, and the
+	// assignments in slip group 0 share the group's lateness.
+	expected := map[string][]float64{
+		"HW 1": {0.8},
+		"HW 2": {0.0},
+		"HW 3": nil,
+		"HW 4": {0.9},
+		"HW 5": {0.9},
+	}
+	for name, factors := range expected {
+		multipliers := outcomes[0].Assignments[name].Grade.MultipliersApplied
+		if len(multipliers) != len(factors) {
+			t.Errorf("%s got multipliers %v; expected factors %v", name, multipliers, factors)
+			continue
+		}
+		for i, multiplier := range multipliers {
+			if multiplier.Factor != factors[i] {
+				t.Errorf("%s got multipliers %v; expected factors %v", name, multipliers, factors)
+			}
+		}
+	}
+}
@@ -1,11 +1,22 @@
 package overrides
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/cs161-staff/grades"
 )
 
+func init() {
+	grades.RegisterPolicy("overrides", func(rawParams json.RawMessage, roster grades.Roster) (grades.Policy, error) {
+		var params map[int]map[string]float64
+		if err := json.Unmarshal(rawParams, &params); err != nil {
+			return nil, err
+		}
+		return Make(params), nil
+	}, "extensions", "slipdays", "latemultipliers")
+}
+
 // Make returns takes in a student ID -> assignment name -> override score map
 // and returns a policy that overrides any score present for a given student's
 // assignment with the new score. A note is also added to indicate the
@@ -19,7 +30,7 @@ func Make(overrides map[int]map[string]float64) grades.Policy {
 		newStudent := student.CloneWithAssignments()
 		for assignmentName, newScore := range studentOverrides {
 			newAssignment := student.Assignments[assignmentName].Clone()
-			newAssignment.Grade.Comments = append(newAssignment.Grade.Comments, fmt.Sprintf("Overridden from %f/%f to %f/%f", newAssignment.Grade.Score, newAssignment.MaxScore, newScore, newAssignment.Grade.Score))
+			newAssignment.Grade.Comments = append(newAssignment.Grade.Comments, fmt.Sprintf("Overridden from %f/%f to %f/%f", newAssignment.Grade.Score, newAssignment.MaxScore, newScore, newAssignment.MaxScore))
 			newAssignment.Grade.Score = newScore
 			newStudent.Assignments[assignmentName] = newAssignment
 		}
@@ -0,0 +1,73 @@
+package schemes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/cs161-staff/grades"
+	"github.com/cs161-staff/grades/policies/weights"
+)
+
+// Scheme is an alternative way of weighting the course's categories and
+// assignments.
+type Scheme struct {
+	// Name is the name of the scheme, which labels the report of each
+	// student graded by it.
+	Name string `json:"name"`
+
+	weights.Params
+}
+
+// Params is the configuration parameters of the schemes policy.
+type Params struct {
+	// Schemes is the alternative grading schemes, in order of preference
+	// when they give the same total.
+	Schemes []*Scheme `json:"schemes"`
+}
+
+func init() {
+	grades.RegisterPolicy("schemes", func(rawParams json.RawMessage, roster grades.Roster) (grades.Policy, error) {
+		var params Params
+		if err := json.Unmarshal(rawParams, &params); err != nil {
+			return nil, err
+		}
+		if len(params.Schemes) == 0 {
+			return nil, errors.New("No grading schemes")
+		}
+		names := make(map[string]bool, len(params.Schemes))
+		for _, scheme := range params.Schemes {
+			if scheme.Name == "" {
+				return nil, errors.New("Grading scheme without a name")
+			}
+			if names[scheme.Name] {
+				return nil, errors.New("Duplicate grading scheme " + scheme.Name)
+			}
+			names[scheme.Name] = true
+			for _, outcomes := range roster {
+				for _, student := range outcomes {
+					if err := scheme.Validate(student); err != nil {
+						return nil, fmt.Errorf("Invalid grading scheme %s: %w", scheme.Name, err)
+					}
+				}
+			}
+		}
+		return Make(params.Schemes), nil
+	})
+}
+
+// Make returns a policy that returns one outcome for each grading scheme, with
+// the scheme's weights applied and the scheme's name noted in the student's
+// comments, so that the student is graded by whichever scheme gives them the
+// highest total.
+func Make(schemes []*Scheme) grades.Policy {
+	return func(student *grades.Student) []*grades.Student {
+		newStudents := make([]*grades.Student, len(schemes))
+		for i, scheme := range schemes {
+			newStudent := scheme.Apply(student)
+			newStudent.Comments = append(append([]string(nil), student.Comments...), "Graded by scheme "+scheme.Name)
+			newStudents[i] = newStudent
+		}
+		return newStudents
+	}
+}
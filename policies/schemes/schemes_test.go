@@ -0,0 +1,42 @@
+package schemes
+
+import (
+	"testing"
+
+	"github.com/cs161-staff/grades"
+	"github.com/cs161-staff/grades/policies/weights"
+)
+
+func TestMake(t *testing.T) {
+	student := &grades.Student{
+		SID: 1,
+		Categories: map[string]*grades.Category{
+			"Homework": {Name: "Homework", Weight: 0.5},
+			"Exams":    {Name: "Exams", Weight: 0.5},
+		},
+		Assignments: map[string]*grades.Assignment{
+			"HW 1":  {Name: "HW 1", CategoryName: "Homework", Weight: 1},
+			"Final": {Name: "Final", CategoryName: "Exams", Weight: 1},
+		},
+	}
+	schemes := []*Scheme{
+		{Name: "A", Params: weights.Params{Categories: map[string]float64{"Exams": 0.4, "Homework": 0.6}}},
+		{Name: "B", Params: weights.Params{Categories: map[string]float64{"Exams": 0.6, "Homework": 0.4}}},
+	}
+	outcomes := Make(schemes)(student)
+	if len(outcomes) != len(schemes) {
+		t.Fatalf("Got %d outcomes; expected %d", len(outcomes), len(schemes))
+	}
+	for i, outcome := range outcomes {
+		expected := "Graded by scheme " + schemes[i].Name
+		if len(outcome.Comments) != 1 || outcome.Comments[0] != expected {
+			t.Errorf("Outcome %d got comments %v; expected %q", i, outcome.Comments, expected)
+		}
+		if outcome.Categories["Exams"].Weight != schemes[i].Categories["Exams"] {
+			t.Errorf("Outcome %d got exams weight %v; expected %v", i, outcome.Categories["Exams"].Weight, schemes[i].Categories["Exams"])
+		}
+	}
+	if len(student.Comments) != 0 || student.Categories["Exams"].Weight != 0.5 {
+		t.Error("Original student was changed")
+	}
+}
@@ -1,11 +1,19 @@
 package slipdays
 
 import (
+	"encoding/json"
+	"sort"
 	"time"
 
 	"github.com/cs161-staff/grades"
 )
 
+func init() {
+	grades.RegisterPolicy("slipdays", func(params json.RawMessage, roster grades.Roster) (grades.Policy, error) {
+		return Apply, nil
+	}, "extensions", "changeslipdays")
+}
+
 // Apply applies a slip days policy. Slip days reduce the lateness of an
 // assignment by one day. Since slip days can be applied in any particular
 // manner and may interact with late policies in arbitrary manners, a
@@ -26,13 +34,20 @@ func apply(student *grades.Student) []*grades.Student {
 	// slipGroupSetPossibilities[i].
 	slipGroupSets := make([]map[int]time.Duration, 0)
 	slipGroupSetPossibilities := make([][]map[int]int, 0)
-	for _, category := range student.Categories {
+	assignmentNames := student.AssignmentNames()
+	for _, categoryName := range student.CategoryNames() {
+		category := student.Categories[categoryName]
+
 		// Find all late slip groups in the category.
 		groupLatenesses := make(map[int]time.Duration)
-		for _, assignment := range student.Assignments {
+		for _, name := range assignmentNames {
+			assignment := student.Assignments[name]
 			if assignment.CategoryName != category.Name {
 				continue
 			}
+			if assignment.SlipGroup < 0 {
+				continue
+			}
 			if assignment.Grade.Lateness > 0 {
 				// The lateness for a slip group is judged by the latest
 				// assignment in the group, so use the max lateness value.
@@ -59,13 +74,19 @@ func apply(student *grades.Student) []*grades.Student {
 			slipGroupSlips := possibility[slipGroupSetIndex]
 			for slipGroup := range slipGroups {
 				slipDays := slipGroupSlips[slipGroup]
-				for _, assignment := range newStudent.Assignments {
+				if slipDays == 0 {
+					continue
+				}
+				for _, name := range assignmentNames {
+					assignment := newStudent.Assignments[name]
 					if assignment.SlipGroup == slipGroup {
 						newAssignment := assignment.Clone()
-						newAssignment.Grade.Lateness -= time.Duration(slipDays * 24)
+						newAssignment.Grade.Lateness -= time.Hour * 24 * time.Duration(slipDays)
+						newAssignment.Grade.SlipDaysApplied += slipDays
 						newStudent.Assignments[assignment.Name] = newAssignment
 					}
 				}
+				newStudent.SlipDaysUsed += slipDays
 			}
 		}
 		newStudents = append(newStudents, newStudent)
@@ -90,17 +111,18 @@ func durationToDays(duration time.Duration) int {
 // that can be assigned.
 func getSlipPossibilities(latenesses map[int]time.Duration, slipDays int) []map[int]int {
 	// Get a list of groups in an ordered slice.
-	groups := make([]int, len(latenesses))
+	groups := make([]int, 0, len(latenesses))
 	for group := range latenesses {
 		groups = append(groups, group)
 	}
+	sort.Ints(groups)
 
 	// The helper function finds all possibilities of assigning slips days to
 	// all groups from index to the end of the groups parameter.
 	var helper func(groups []int, index int, daysLeft int) []map[int]int
 	helper = func(groups []int, index int, daysLeft int) []map[int]int {
 		if index == len(groups) {
-			return []map[int]int{}
+			return []map[int]int{{}}
 		}
 
 		// Apply 0 to the max number of slip days to the cururent group and
@@ -131,7 +153,7 @@ func getSlipPossibilities(latenesses map[int]time.Duration, slipDays int) []map[
 // Returns the cross product of the given slices.
 func crossProduct(slices ...[]map[int]int) [][]map[int]int {
 	if len(slices) == 0 {
-		return [][]map[int]int{}
+		return [][]map[int]int{{}}
 	}
 
 	// Get length of the cross product so that allocation can be done at once.
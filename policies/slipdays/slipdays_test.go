@@ -3,8 +3,45 @@ package slipdays
 import (
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/cs161-staff/grades"
 )
 
+func TestApply(t *testing.T) {
+	student := &grades.Student{
+		Categories: map[string]*grades.Category{
+			"Homework": {Name: "Homework", SlipDays: 2},
+		},
+		Assignments: map[string]*grades.Assignment{
+			"HW 1": {Name: "HW 1", CategoryName: "Homework", SlipGroup: 1, Grade: grades.AssignmentSubmission{Lateness: 30 * time.Hour}},
+			"HW 2": {Name: "HW 2", CategoryName: "Homework", SlipGroup: -1, Grade: grades.AssignmentSubmission{Lateness: 10 * time.Hour}},
+		},
+	}
+	latenesses := make([]time.Duration, 0)
+	for _, outcome := range apply(student) {
+		if lateness := outcome.Assignments["HW 2"].Grade.Lateness; lateness != 10*time.Hour {
+			t.Errorf("Slip days used on an assignment without a slip group: lateness %v", lateness)
+		}
+		latenesses = append(latenesses, outcome.Assignments["HW 1"].Grade.Lateness)
+	}
+	// Each slip day removes a whole day of lateness.
+	expected := []time.Duration{30 * time.Hour, 6 * time.Hour, -18 * time.Hour}
+	if !reflect.DeepEqual(latenesses, expected) {
+		t.Errorf("Got latenesses %v; expected %v", latenesses, expected)
+	}
+}
+
+func TestGetSlipPossibilities(t *testing.T) {
+	if possibilities := getSlipPossibilities(map[int]time.Duration{}, 2); !reflect.DeepEqual(possibilities, []map[int]int{{}}) {
+		t.Errorf("Got possibilities %v without slip groups; expected one empty possibility", possibilities)
+	}
+	expected := []map[int]int{{1: 0}, {1: 1}, {1: 2}}
+	if possibilities := getSlipPossibilities(map[int]time.Duration{1: 30 * time.Hour}, 3); !reflect.DeepEqual(possibilities, expected) {
+		t.Errorf("Got possibilities %v; expected %v", possibilities, expected)
+	}
+}
+
 func TestCrossProduct(t *testing.T) {
 	a := []map[int]int{{1: 1}, {2: 2}, {3: 3}}
 	b := []map[int]int{{4: 4}, {5: 5}, {6: 6}}
@@ -42,4 +79,7 @@ func TestCrossProduct(t *testing.T) {
 	if !reflect.DeepEqual(cross, expected) {
 		t.Fail()
 	}
+	if cross := crossProduct(); !reflect.DeepEqual(cross, [][]map[int]int{{}}) {
+		t.Errorf("Got cross product %v of no slices; expected one empty combination", cross)
+	}
 }
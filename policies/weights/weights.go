@@ -0,0 +1,194 @@
+package weights
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/cs161-staff/grades"
+)
+
+// Transfer moves the whole weight of one category or assignment onto another.
+// Both must be categories, or both must be assignments in the same category.
+type Transfer struct {
+	// From is the name of the category or assignment whose weight is moved.
+	From string `json:"from"`
+
+	// To is the name of the category or assignment that receives the weight.
+	To string `json:"to"`
+}
+
+// Params is a student's weight changes.
+type Params struct {
+	// Categories is the new weight of each category, by name.
+	Categories map[string]float64 `json:"categories,omitempty"`
+
+	// Assignments is the new weight of each assignment within its category,
+	// by name.
+	Assignments map[string]float64 `json:"assignments,omitempty"`
+
+	// Transfers is the weight transfers, applied in order after the new
+	// weights.
+	Transfers []Transfer `json:"transfers,omitempty"`
+}
+
+func init() {
+	grades.RegisterPolicy("weights", func(rawParams json.RawMessage, roster grades.Roster) (grades.Policy, error) {
+		var params map[int]*Params
+		if err := json.Unmarshal(rawParams, &params); err != nil {
+			return nil, err
+		}
+		for sid, studentParams := range params {
+			if outcomes, ok := roster[sid]; ok && len(outcomes) > 0 {
+				if err := studentParams.Validate(outcomes[0]); err != nil {
+					return nil, fmt.Errorf("Invalid weights for SID %d: %w", sid, err)
+				}
+			}
+		}
+		return Make(params), nil
+	}, "schemes")
+}
+
+// Validate checks that the categories and assignments named in the params
+// exist for the student and that each transfer is between two categories or
+// two assignments in the same category.
+func (params *Params) Validate(student *grades.Student) error {
+	for name, weight := range params.Categories {
+		if _, ok := student.Categories[name]; !ok {
+			return fmt.Errorf("Unknown category %s", name)
+		}
+		if weight < 0.0 {
+			return fmt.Errorf("Negative weight for category %s", name)
+		}
+	}
+	for name, weight := range params.Assignments {
+		if _, ok := student.Assignments[name]; !ok {
+			return fmt.Errorf("Unknown assignment %s", name)
+		}
+		if weight < 0.0 {
+			return fmt.Errorf("Negative weight for assignment %s", name)
+		}
+	}
+	for _, transfer := range params.Transfers {
+		_, fromCategory := student.Categories[transfer.From]
+		_, toCategory := student.Categories[transfer.To]
+		fromAssignment, fromIsAssignment := student.Assignments[transfer.From]
+		toAssignment, toIsAssignment := student.Assignments[transfer.To]
+		switch {
+		case transfer.From == transfer.To:
+			return fmt.Errorf("Transfer from %s to itself", transfer.From)
+		case fromCategory && toCategory && (fromIsAssignment || toIsAssignment):
+			return fmt.Errorf("Transfer from %s to %s is ambiguous, since they name both categories and assignments", transfer.From, transfer.To)
+		case fromCategory && toCategory:
+		case fromIsAssignment && toIsAssignment:
+			if fromAssignment.CategoryName != toAssignment.CategoryName {
+				return fmt.Errorf("Transfer from %s to %s is between assignments in different categories", transfer.From, transfer.To)
+			}
+		default:
+			return fmt.Errorf("Transfer from %s to %s must be between two categories or two assignments", transfer.From, transfer.To)
+		}
+	}
+	return nil
+}
+
+// Make takes in a student ID -> weight changes map and returns a policy that
+// changes the weights of categories and assignments for the specified
+// students with Apply, returning it as the only new outcome for the student.
+func Make(params map[int]*Params) grades.Policy {
+	return func(student *grades.Student) []*grades.Student {
+		studentParams, ok := params[student.SID]
+		if !ok {
+			return []*grades.Student{student}
+		}
+		return []*grades.Student{studentParams.Apply(student)}
+	}
+}
+
+// Apply returns a copy of the student with the weight changes applied.
+// Category weights are then scaled so that they sum to the same total as
+// before, so that total scores stay on the same scale. Each changed weight is
+// noted in the category or assignment's comments. Names that the student does
+// not have are ignored.
+func (params *Params) Apply(student *grades.Student) *grades.Student {
+	categoryNames := student.CategoryNames()
+	assignmentNames := student.AssignmentNames()
+	categoryWeights := make(map[string]float64, len(categoryNames))
+	for _, name := range categoryNames {
+		categoryWeights[name] = student.Categories[name].Weight
+	}
+	assignmentWeights := make(map[string]float64, len(assignmentNames))
+	for _, name := range assignmentNames {
+		assignmentWeights[name] = student.Assignments[name].Weight
+	}
+	originalSum := sum(categoryWeights, categoryNames)
+
+	for name, weight := range params.Categories {
+		if _, ok := categoryWeights[name]; ok {
+			categoryWeights[name] = weight
+		}
+	}
+	for name, weight := range params.Assignments {
+		if _, ok := assignmentWeights[name]; ok {
+			assignmentWeights[name] = weight
+		}
+	}
+	transferComments := make(map[string][]string)
+	for _, transfer := range params.Transfers {
+		weights := assignmentWeights
+		if _, ok := student.Categories[transfer.From]; ok {
+			weights = categoryWeights
+		}
+		_, fromOk := weights[transfer.From]
+		_, toOk := weights[transfer.To]
+		if !fromOk || !toOk || transfer.From == transfer.To {
+			continue
+		}
+		weights[transfer.To] += weights[transfer.From]
+		weights[transfer.From] = 0.0
+		transferComments[transfer.From] = append(transferComments[transfer.From], "Weight transferred to "+transfer.To)
+		transferComments[transfer.To] = append(transferComments[transfer.To], "Weight transferred from "+transfer.From)
+	}
+
+	// Transfers keep the sum the same, so only scale the weights if they
+	// were changed, to avoid changing them by rounding errors.
+	if newSum := sum(categoryWeights, categoryNames); newSum > 0.0 && math.Abs(newSum-originalSum) > 1e-9*originalSum {
+		for _, name := range categoryNames {
+			categoryWeights[name] *= originalSum / newSum
+		}
+	}
+
+	newStudent := student.CloneWithCategories().CloneWithAssignments()
+	for _, name := range categoryNames {
+		category := student.Categories[name]
+		if categoryWeights[name] == category.Weight {
+			continue
+		}
+		newCategory := category.Clone()
+		newCategory.Comments = append(newCategory.Comments, transferComments[name]...)
+		newCategory.Comments = append(newCategory.Comments, fmt.Sprintf("Weight changed from %g to %g", category.Weight, categoryWeights[name]))
+		newCategory.Weight = categoryWeights[name]
+		newStudent.Categories[name] = newCategory
+	}
+	for _, name := range assignmentNames {
+		assignment := student.Assignments[name]
+		if assignmentWeights[name] == assignment.Weight {
+			continue
+		}
+		newAssignment := assignment.Clone()
+		newAssignment.Grade.Comments = append(newAssignment.Grade.Comments, transferComments[name]...)
+		newAssignment.Grade.Comments = append(newAssignment.Grade.Comments, fmt.Sprintf("Weight changed from %g to %g", assignment.Weight, assignmentWeights[name]))
+		newAssignment.Weight = assignmentWeights[name]
+		newStudent.Assignments[name] = newAssignment
+	}
+	return newStudent
+}
+
+// sum returns the sum of the weights with the given names, in order, so that
+// the sum is the same from run to run.
+func sum(weights map[string]float64, names []string) float64 {
+	total := 0.0
+	for _, name := range names {
+		total += weights[name]
+	}
+	return total
+}
@@ -0,0 +1,51 @@
+package weights
+
+import (
+	"testing"
+
+	"github.com/cs161-staff/grades"
+)
+
+func TestMake(t *testing.T) {
+	student := &grades.Student{
+		SID: 1,
+		Categories: map[string]*grades.Category{
+			"Homework": {Name: "Homework", Weight: 0.5},
+			"Exams":    {Name: "Exams", Weight: 0.5},
+		},
+		Assignments: map[string]*grades.Assignment{
+			"HW 1":    {Name: "HW 1", CategoryName: "Homework", Weight: 1},
+			"Midterm": {Name: "Midterm", CategoryName: "Exams", Weight: 1},
+			"Final":   {Name: "Final", CategoryName: "Exams", Weight: 2},
+		},
+	}
+	params := &Params{
+		Categories: map[string]float64{"Exams": 1.5},
+		Transfers:  []Transfer{{From: "Midterm", To: "Final"}},
+	}
+	if err := params.Validate(student); err != nil {
+		t.Fatal(err)
+	}
+	outcomes := Make(map[int]*Params{1: params})(student)
+	if len(outcomes) != 1 {
+		t.Fatalf("Got %d outcomes; expected 1", len(outcomes))
+	}
+	newStudent := outcomes[0]
+	if newStudent.Categories["Exams"].Weight != 0.75 || newStudent.Categories["Homework"].Weight != 0.25 {
+		t.Errorf("Got category weights %v and %v; expected 0.75 and 0.25", newStudent.Categories["Exams"].Weight, newStudent.Categories["Homework"].Weight)
+	}
+	if newStudent.Assignments["Midterm"].Weight != 0 || newStudent.Assignments["Final"].Weight != 3 {
+		t.Errorf("Got assignment weights %v and %v; expected 0 and 3", newStudent.Assignments["Midterm"].Weight, newStudent.Assignments["Final"].Weight)
+	}
+	if len(newStudent.Assignments["Final"].Grade.Comments) != 2 {
+		t.Errorf("Got comments %v", newStudent.Assignments["Final"].Grade.Comments)
+	}
+	if student.Categories["Exams"].Weight != 0.5 || student.Assignments["Final"].Weight != 2 {
+		t.Error("Original student was changed")
+	}
+
+	invalid := &Params{Transfers: []Transfer{{From: "HW 1", To: "Final"}}}
+	if err := invalid.Validate(student); err == nil {
+		t.Error("Transfer between assignments in different categories accepted")
+	}
+}
@@ -0,0 +1,65 @@
+package grades
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WriteReportsCSV writes the grade reports to the writer as a CSV with one row
+// per student, sorted by SID. Each row has the raw, adjusted and weighted
+// scores of the assignments and then the categories with the given names, in
+// the given order, followed by the total, letter grade, final grade and all of
+// the report's comments. Scores are written as percentages rounded to the
+// given number of decimal places.
+func WriteReportsCSV(writer io.Writer, reports map[int]*GradeReport, categoryNames []string, assignmentNames []string, rounding int) error {
+	header := []string{"SID", "Name"}
+	for _, name := range assignmentNames {
+		header = append(header, name+" Raw", name+" Adjusted", name+" Weighted")
+	}
+	for _, name := range categoryNames {
+		name = CategoryColumnPrefix + name
+		header = append(header, name+" Raw", name+" Adjusted", name+" Weighted")
+	}
+	header = append(header, "Total", "Letter", "Grade", "Comments")
+
+	sids := make([]int, 0, len(reports))
+	for sid := range reports {
+		sids = append(sids, sid)
+	}
+	sort.Ints(sids)
+
+	csvWriter := csv.NewWriter(writer)
+	csvWriter.Write(header)
+	for _, sid := range sids {
+		report := reports[sid]
+		row := []string{strconv.Itoa(sid), report.Student.Name}
+		for _, name := range assignmentNames {
+			assignmentReport := report.Assignments[name]
+			row = append(row,
+				FormatPercent(assignmentReport.Raw, rounding),
+				FormatPercent(assignmentReport.Adjusted, rounding),
+				FormatPercent(assignmentReport.Weighted, rounding),
+			)
+		}
+		for _, name := range categoryNames {
+			categoryReport := report.Categories[name]
+			row = append(row,
+				FormatPercent(categoryReport.Raw, rounding),
+				FormatPercent(categoryReport.Adjusted, rounding),
+				FormatPercent(categoryReport.Weighted, rounding),
+			)
+		}
+		row = append(row,
+			FormatPercent(report.TotalScore, rounding),
+			report.Letter,
+			report.Grade,
+			strings.Join(report.AllComments(), "; "),
+		)
+		csvWriter.Write(row)
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
@@ -0,0 +1,173 @@
+package grades
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// reportJSON is the JSON format of a finalized grade report. Only the
+// student's identifying information is included, not the inputs to their
+// grade.
+type reportJSON struct {
+	SID          int                         `json:"sid"`
+	Name         string                      `json:"name"`
+	Email        string                      `json:"email,omitempty"`
+	GradingBasis GradingBasis                `json:"grading_basis"`
+	TotalScore   float64                     `json:"total"`
+	Letter       string                      `json:"letter,omitempty"`
+	Grade        string                      `json:"grade,omitempty"`
+	Categories   map[string]*reportScoreJSON `json:"categories"`
+	Assignments  map[string]*reportScoreJSON `json:"assignments"`
+	Comments     []string                    `json:"comments,omitempty"`
+	Pending      []string                    `json:"pending,omitempty"`
+}
+
+// reportScoreJSON is the JSON format of a category or assignment on a report.
+type reportScoreJSON struct {
+	Raw      float64  `json:"raw"`
+	Adjusted float64  `json:"adjusted"`
+	Weighted float64  `json:"weighted"`
+	Comments []string `json:"comments,omitempty"`
+}
+
+// newReportJSON returns the report in the JSON format of finalized grade
+// reports.
+func newReportJSON(report *GradeReport) (*reportJSON, error) {
+	if report.Student == nil {
+		return nil, errors.New("Grade report has no student")
+	}
+	entry := &reportJSON{
+		SID:          report.Student.SID,
+		Name:         report.Student.Name,
+		Email:        report.Student.Email,
+		GradingBasis: report.Student.GradingBasis,
+		TotalScore:   report.TotalScore,
+		Letter:       report.Letter,
+		Grade:        report.Grade,
+		Categories:   make(map[string]*reportScoreJSON, len(report.Categories)),
+		Assignments:  make(map[string]*reportScoreJSON, len(report.Assignments)),
+		Comments:     report.Comments,
+		Pending:      report.Pending,
+	}
+	for name, category := range report.Categories {
+		entry.Categories[name] = &reportScoreJSON{
+			Raw:      category.Raw,
+			Adjusted: category.Adjusted,
+			Weighted: category.Weighted,
+			Comments: category.Comments,
+		}
+	}
+	for name, assignment := range report.Assignments {
+		entry.Assignments[name] = &reportScoreJSON{
+			Raw:      assignment.Raw,
+			Adjusted: assignment.Adjusted,
+			Weighted: assignment.Weighted,
+			Comments: assignment.Comments,
+		}
+	}
+	return entry, nil
+}
+
+// report returns the grade report decoded from the JSON format. The report's
+// student only has its SID, name, email and grading basis set.
+func (entry *reportJSON) report() (*GradeReport, error) {
+	report := &GradeReport{
+		Student: &Student{
+			SID:          entry.SID,
+			Name:         entry.Name,
+			Email:        entry.Email,
+			GradingBasis: entry.GradingBasis,
+		},
+		TotalScore:  entry.TotalScore,
+		Letter:      entry.Letter,
+		Grade:       entry.Grade,
+		Categories:  make(map[string]*ReportCategory, len(entry.Categories)),
+		Assignments: make(map[string]*ReportAssignment, len(entry.Assignments)),
+		Comments:    entry.Comments,
+		Pending:     entry.Pending,
+	}
+	for name, category := range entry.Categories {
+		if category == nil {
+			return nil, fmt.Errorf("Null category %s in grade report for SID %d", name, entry.SID)
+		}
+		report.Categories[name] = &ReportCategory{
+			Raw:      category.Raw,
+			Adjusted: category.Adjusted,
+			Weighted: category.Weighted,
+			Comments: category.Comments,
+		}
+	}
+	for name, assignment := range entry.Assignments {
+		if assignment == nil {
+			return nil, fmt.Errorf("Null assignment %s in grade report for SID %d", name, entry.SID)
+		}
+		report.Assignments[name] = &ReportAssignment{
+			Raw:      assignment.Raw,
+			Adjusted: assignment.Adjusted,
+			Weighted: assignment.Weighted,
+			Comments: assignment.Comments,
+		}
+	}
+	return report, nil
+}
+
+// MarshalReportJSON encodes a single grade report in the format that
+// WriteReportsJSON uses for each report.
+func MarshalReportJSON(report *GradeReport) ([]byte, error) {
+	entry, err := newReportJSON(report)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(entry)
+}
+
+// WriteReportsJSON writes the grade reports to the writer as a JSON array
+// sorted by SID.
+func WriteReportsJSON(writer io.Writer, reports map[int]*GradeReport) error {
+	sids := make([]int, 0, len(reports))
+	for sid := range reports {
+		sids = append(sids, sid)
+	}
+	sort.Ints(sids)
+
+	sorted := make([]*reportJSON, len(sids))
+	for i, sid := range sids {
+		entry, err := newReportJSON(reports[sid])
+		if err != nil {
+			return err
+		}
+		sorted[i] = entry
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sorted)
+}
+
+// ReadReportsJSON reads grade reports written by WriteReportsJSON, keyed by
+// SID. Each report's student only has its SID, name and email set.
+func ReadReportsJSON(reader io.Reader) (map[int]*GradeReport, error) {
+	var sorted []*reportJSON
+	if err := json.NewDecoder(reader).Decode(&sorted); err != nil {
+		return nil, err
+	}
+
+	reports := make(map[int]*GradeReport, len(sorted))
+	for _, entry := range sorted {
+		if entry == nil {
+			return nil, errors.New("Null grade report in JSON")
+		}
+		report, err := entry.report()
+		if err != nil {
+			return nil, err
+		}
+		sid := report.Student.SID
+		if _, ok := reports[sid]; ok {
+			return nil, fmt.Errorf("Duplicate grade report for SID %d", sid)
+		}
+		reports[sid] = report
+	}
+	return reports, nil
+}
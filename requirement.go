@@ -0,0 +1,197 @@
+package grades
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// RequirementType is the kind of condition checked by a Requirement.
+type RequirementType string
+
+const (
+	// RequirementMinCategoryScore requires the adjusted score in a category to
+	// be at least a minimum.
+	RequirementMinCategoryScore RequirementType = "min_category_score"
+
+	// RequirementMandatoryAssignment requires an assignment to be submitted.
+	RequirementMandatoryAssignment RequirementType = "mandatory_assignment"
+
+	// RequirementMaxMissing requires at most a number of assignments, either
+	// in a category or in the whole course, to be missing. Dropped
+	// assignments are not counted.
+	RequirementMaxMissing RequirementType = "max_missing"
+)
+
+// Requirement is a condition that a student must meet to receive their letter
+// grade, regardless of their total score. Students who do not meet it have
+// their letter grade capped at MaxLetter or replaced by Letter.
+type Requirement struct {
+	// Name is the human-readable name of the requirement used in comments. If
+	// it is empty, a name is generated from the condition.
+	Name string
+
+	// Type is the kind of condition checked.
+	Type RequirementType
+
+	// GradingBases is the grading bases of the students that the requirement
+	// applies to. If it is empty, it applies to every student.
+	GradingBases []GradingBasis
+
+	// Category is the category checked, if any.
+	Category string
+
+	// Assignment is the assignment checked, if any.
+	Assignment string
+
+	// MinScore is the minimum adjusted category score, from 0 to 1.
+	MinScore float64
+
+	// MaxMissing is the maximum number of missing assignments.
+	MaxMissing int
+
+	// MaxLetter is the highest letter grade that students who do not meet the
+	// requirement may receive.
+	MaxLetter string
+
+	// Letter is the letter grade that students who do not meet the
+	// requirement receive.
+	Letter string
+}
+
+// String returns the requirement's name, generating one from the condition if
+// it has none.
+func (requirement *Requirement) String() string {
+	if requirement.Name != "" {
+		return requirement.Name
+	}
+	switch requirement.Type {
+	case RequirementMinCategoryScore:
+		score := strconv.FormatFloat(requirement.MinScore*100.0, 'f', -1, 64)
+		return fmt.Sprintf("at least %s%% in %s", score, requirement.Category)
+	case RequirementMandatoryAssignment:
+		return requirement.Assignment + " submitted"
+	case RequirementMaxMissing:
+		if requirement.Category != "" {
+			return fmt.Sprintf("at most %d missing %s assignments", requirement.MaxMissing, requirement.Category)
+		}
+		return fmt.Sprintf("at most %d missing assignments", requirement.MaxMissing)
+	}
+	return string(requirement.Type)
+}
+
+// Validate checks that the requirement refers to categories, assignments and
+// letter grades that exist in the course configuration.
+func (requirement *Requirement) Validate(config *CourseConfig) error {
+	switch requirement.Type {
+	case RequirementMinCategoryScore:
+		if _, ok := config.Categories[requirement.Category]; !ok {
+			return fmt.Errorf("Requirement %q references unknown category %s", requirement, requirement.Category)
+		}
+		if requirement.MinScore < 0.0 || requirement.MinScore > 1.0 {
+			return fmt.Errorf("Requirement %q has a minimum score outside 0 to 1", requirement)
+		}
+	case RequirementMandatoryAssignment:
+		if _, ok := config.Assignments[requirement.Assignment]; !ok {
+			return fmt.Errorf("Requirement %q references unknown assignment %s", requirement, requirement.Assignment)
+		}
+	case RequirementMaxMissing:
+		if _, ok := config.Categories[requirement.Category]; !ok && requirement.Category != "" {
+			return fmt.Errorf("Requirement %q references unknown category %s", requirement, requirement.Category)
+		}
+		if requirement.MaxMissing < 0 {
+			return fmt.Errorf("Requirement %q has a negative maximum number of missing assignments", requirement)
+		}
+	default:
+		return fmt.Errorf("Unknown requirement type %q", requirement.Type)
+	}
+	if (requirement.MaxLetter == "") == (requirement.Letter == "") {
+		return fmt.Errorf("Requirement %q must have exactly one of a maximum letter grade and a letter grade", requirement)
+	}
+	for _, letter := range []string{requirement.MaxLetter, requirement.Letter} {
+		if _, ok := config.GradeBins.Min(letter); !ok && letter != "" {
+			return fmt.Errorf("Requirement %q references unknown letter grade %s", requirement, letter)
+		}
+	}
+	return nil
+}
+
+// Met returns whether the student in the grade report meets the requirement.
+// Students with a grading basis that the requirement does not apply to always
+// meet it.
+func (requirement *Requirement) Met(report *GradeReport) bool {
+	if len(requirement.GradingBases) > 0 {
+		applies := false
+		for _, basis := range requirement.GradingBases {
+			applies = applies || basis == report.Student.GradingBasis
+		}
+		if !applies {
+			return true
+		}
+	}
+	switch requirement.Type {
+	case RequirementMinCategoryScore:
+		category, ok := report.Categories[requirement.Category]
+		return ok && category.Adjusted >= requirement.MinScore
+	case RequirementMandatoryAssignment:
+		assignment, ok := report.Student.Assignments[requirement.Assignment]
+		return ok && assignment.Grade.Status != StatusMissing
+	case RequirementMaxMissing:
+		missing := 0
+		for _, assignment := range report.Student.Assignments {
+			if requirement.Category != "" && assignment.CategoryName != requirement.Category {
+				continue
+			}
+			if assignment.Grade.Status == StatusMissing && !assignment.Grade.Dropped {
+				missing++
+			}
+		}
+		return missing <= requirement.MaxMissing
+	}
+	return false
+}
+
+// Apply changes the letter grade of the report if the student does not meet
+// the requirement, adding a comment naming the requirement. Letter grades are
+// compared by the minimum total scores of their bins.
+func (requirement *Requirement) Apply(report *GradeReport, bins GradeBins) {
+	if requirement.Met(report) {
+		return
+	}
+	if requirement.Letter != "" {
+		if report.Letter != requirement.Letter {
+			report.Letter = requirement.Letter
+			report.Comments = append(report.Comments, fmt.Sprintf("Requirement not met: %s (letter grade set to %s)", requirement, requirement.Letter))
+		}
+		return
+	}
+	maxMin, _ := bins.Min(requirement.MaxLetter)
+	min, ok := bins.Min(report.Letter)
+	if !ok {
+		min = math.Inf(1)
+	}
+	if min > maxMin {
+		report.Letter = requirement.MaxLetter
+		report.Comments = append(report.Comments, fmt.Sprintf("Requirement not met: %s (letter grade capped at %s)", requirement, requirement.MaxLetter))
+	}
+}
+
+// AssignGrade sets the letter grade of the report from the course's grade
+// bins, applies each of the course's requirements in order, and then sets the
+// final grade for the student's grading basis. Students with pending
+// assignments receive IncompleteGrade instead.
+func (config *CourseConfig) AssignGrade(report *GradeReport) error {
+	report.Letter = config.GradeBins.Letter(report.TotalScore)
+	for _, requirement := range config.Requirements {
+		requirement.Apply(report, config.GradeBins)
+	}
+	if assignIncomplete(report) {
+		return nil
+	}
+	grade, err := config.Grade(report.Letter, report.Student.GradingBasis)
+	if err != nil {
+		return fmt.Errorf("SID %d: %w", report.Student.SID, err)
+	}
+	report.Grade = grade
+	return nil
+}
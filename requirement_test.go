@@ -0,0 +1,64 @@
+package grades
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRequirements(t *testing.T) {
+	config, err := ReadCourseConfig(strings.NewReader(`{
+		"categories": [
+			{"name": "Homework", "weight": 0.5},
+			{"name": "Exams", "weight": 0.5}
+		],
+		"assignments": [
+			{"name": "HW 1", "category": "Homework", "max_score": 10},
+			{"name": "HW 2", "category": "Homework", "max_score": 10},
+			{"name": "Final", "category": "Exams", "max_score": 100}
+		],
+		"requirements": [
+			{"name": "50% on exams", "type": "min_category_score", "category": "Exams", "min_score": 0.5, "letter": "F"},
+			{"type": "max_missing", "category": "Homework", "max_missing": 1, "max_letter": "C"}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := func(homework SubmissionStatus, exams float64) *GradeReport {
+		return &GradeReport{
+			Student: &Student{Assignments: map[string]*Assignment{
+				"HW 1":  {CategoryName: "Homework", Grade: AssignmentSubmission{Status: homework}},
+				"HW 2":  {CategoryName: "Homework", Grade: AssignmentSubmission{Status: homework}},
+				"Final": {CategoryName: "Exams"},
+			}},
+			TotalScore: 0.95,
+			Categories: map[string]*ReportCategory{"Exams": {Adjusted: exams}},
+		}
+	}
+	cases := []struct {
+		report   *GradeReport
+		letter   string
+		comments int
+	}{
+		{report(StatusGraded, 0.9), "A", 0},
+		{report(StatusGraded, 0.4), "F", 1},
+		{report(StatusMissing, 0.9), "C", 1},
+		{report(StatusMissing, 0.4), "F", 1},
+	}
+	for i, c := range cases {
+		if err := config.AssignGrade(c.report); err != nil {
+			t.Fatal(err)
+		}
+		if c.report.Letter != c.letter || len(c.report.Comments) != c.comments {
+			t.Errorf("Case %d got letter %s with comments %v; expected %s with %d comments", i, c.report.Letter, c.report.Comments, c.letter, c.comments)
+		}
+	}
+
+	if _, err := ReadCourseConfig(strings.NewReader(`{
+		"categories": [{"name": "Exams", "weight": 1}],
+		"requirements": [{"type": "min_category_score", "category": "Exams", "min_score": 0.5, "letter": "Z"}]
+	}`)); err == nil {
+		t.Error("Requirement with an unknown letter grade accepted")
+	}
+}
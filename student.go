@@ -2,6 +2,7 @@ package grades
 
 import (
 	"fmt"
+	"sort"
 )
 
 // Student is a student whose grade is being calculated.
@@ -12,6 +13,15 @@ type Student struct {
 	// Name is the student's name.
 	Name string
 
+	// Email is the student's email address.
+	Email string
+
+	// Units is the number of units the student is enrolled for.
+	Units float64
+
+	// GradingBasis is the basis on which the student takes the course.
+	GradingBasis GradingBasis
+
 	// Categories is the categories relevant to the student.
 	Categories map[string]*Category
 
@@ -20,11 +30,41 @@ type Student struct {
 
 	// SlipDaysUsed tracks how many slip days the student has used so far.
 	SlipDaysUsed int
+
+	// Comments is the human-readable comments added to the student as a
+	// whole, which are copied to their grade report.
+	Comments []string
+}
+
+// NewStudent returns a student with its own copies of the given categories and
+// assignments. Each assignment's grade is taken from the submissions map, and
+// assignments without a submission are marked as missing.
+func NewStudent(sid int, name string, categories map[string]*Category, assignments map[string]*Assignment, submissions map[string]AssignmentSubmission) *Student {
+	student := &Student{
+		SID:         sid,
+		Name:        name,
+		Categories:  make(map[string]*Category, len(categories)),
+		Assignments: make(map[string]*Assignment, len(assignments)),
+	}
+	for name, category := range categories {
+		student.Categories[name] = category.Clone()
+	}
+	for name, assignment := range assignments {
+		newAssignment := assignment.Clone()
+		if submission, ok := submissions[name]; ok {
+			newAssignment.Grade = submission
+		} else {
+			newAssignment.Grade = AssignmentSubmission{Status: StatusMissing}
+		}
+		student.Assignments[name] = newAssignment
+	}
+	return student
 }
 
 // Clone returns a shallow copy of the student.
 func (student *Student) Clone() *Student {
-	return &*student
+	newStudent := *student
+	return &newStudent
 }
 
 // CloneWithCategories returns a shallow copy of the student with a new
@@ -49,14 +89,58 @@ func (student *Student) CloneWithAssignments() *Student {
 	return newStudent
 }
 
+// CategoryNames returns the names of the student's categories in sorted order.
+// Policies and reports iterate over categories in this order rather than over
+// the map so that their results are the same from run to run.
+func (student *Student) CategoryNames() []string {
+	names := make([]string, 0, len(student.Categories))
+	for name := range student.Categories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AssignmentNames returns the names of the student's assignments in sorted
+// order. Policies and reports iterate over assignments in this order rather
+// than over the map so that their results are the same from run to run.
+func (student *Student) AssignmentNames() []string {
+	names := make([]string, 0, len(student.Assignments))
+	for name := range student.Assignments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // GenerateGradeReport generates a GradeReport based on the student's current
-// information.
+// information. Pending assignments are left out of their category's score. A
+// category whose assignments are all pending scores 0 but keeps its weight,
+// so the total is a lower bound until the pending work is graded; such
+// categories are noted in a comment.
 func (student *Student) GenerateGradeReport() *GradeReport {
-	gradeReport := &GradeReport{}
+	gradeReport := &GradeReport{
+		Student:     student,
+		Categories:  make(map[string]*ReportCategory, len(student.Categories)),
+		Assignments: make(map[string]*ReportAssignment, len(student.Assignments)),
+	}
+	if len(student.Comments) > 0 {
+		gradeReport.Comments = make([]string, len(student.Comments))
+		copy(gradeReport.Comments, student.Comments)
+	}
+
+	assignmentNames := student.AssignmentNames()
 
 	// Build assignment reports.
-	for _, assignment := range student.Assignments {
-		rawScore := assignment.Grade.Score / assignment.MaxScore
+	for _, name := range assignmentNames {
+		assignment := student.Assignments[name]
+		if assignment.Grade.Status == StatusPending {
+			gradeReport.Pending = append(gradeReport.Pending, name)
+		}
+		var rawScore float64
+		if assignment.MaxScore > 0.0 {
+			rawScore = assignment.Grade.Score / assignment.MaxScore
+		}
 		comments := make([]string, len(assignment.Grade.Comments))
 		for i, comment := range assignment.Grade.Comments {
 			comments[i] = comment
@@ -81,17 +165,24 @@ func (student *Student) GenerateGradeReport() *GradeReport {
 	}
 
 	// Build category reports and total score.
-	for _, category := range student.Categories {
+	for _, categoryName := range student.CategoryNames() {
+		category := student.Categories[categoryName]
+
 		// Track total numerator as sum of assignments' adjusted score * weight
 		// and denominator as sum of weights.
 		categoryNumerator := 0.0
 		categoryDenominator := 0.0
+		categoryPending := false
 
-		for _, assignment := range student.Assignments {
+		for _, name := range assignmentNames {
+			assignment := student.Assignments[name]
 			if assignment.CategoryName != category.Name {
 				continue
 			}
-			if assignment.Grade.Dropped {
+			if assignment.Grade.Status == StatusPending && !assignment.Grade.Dropped {
+				categoryPending = true
+			}
+			if assignment.Grade.Dropped || assignment.Grade.Status == StatusPending {
 				continue
 			}
 
@@ -110,13 +201,16 @@ func (student *Student) GenerateGradeReport() *GradeReport {
 		for i, comment := range category.Comments {
 			comments[i] = comment
 		}
+		if categoryDenominator == 0.0 && categoryPending {
+			comments = append(comments, "Every assignment is pending; counted as 0 until graded")
+		}
 		var adjustedScore float64
 		if override, present := category.Override(); present {
 			adjustedScore = override
 		} else {
 			adjustedScore = rawScore
 		}
-		weightedScore := adjustedScore / category.Weight
+		weightedScore := adjustedScore * category.Weight
 		gradeReport.Categories[category.Name] = &ReportCategory{
 			Raw:      rawScore,
 			Adjusted: adjustedScore,
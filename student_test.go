@@ -0,0 +1,63 @@
+package grades
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	student := &Student{SID: 1}
+	clone := student.Clone()
+	clone.SID = 2
+	if clone == student || student.SID != 1 {
+		t.Error("Clone returned the same student")
+	}
+}
+
+func TestGenerateGradeReport(t *testing.T) {
+	student := &Student{
+		Categories: map[string]*Category{
+			"Homework": {Name: "Homework", Weight: 0.25},
+			"Exams":    {Name: "Exams", Weight: 0.75},
+		},
+		Assignments: map[string]*Assignment{
+			"HW 1":  {Name: "HW 1", CategoryName: "Homework", MaxScore: 10, Weight: 1, Grade: AssignmentSubmission{Score: 5}},
+			"HW 2":  {Name: "HW 2", CategoryName: "Homework", MaxScore: 0, Weight: 1, Grade: AssignmentSubmission{Score: 1}},
+			"Final": {Name: "Final", CategoryName: "Exams", MaxScore: 100, Weight: 1, Grade: AssignmentSubmission{Score: 80}},
+		},
+	}
+	report := student.GenerateGradeReport()
+	if raw := report.Assignments["HW 2"].Raw; raw != 0.0 {
+		t.Errorf("Got raw score %v for an assignment with a max score of 0; expected 0", raw)
+	}
+	// Weighted category scores are the adjusted score times the weight.
+	if weighted := report.Categories["Exams"].Weighted; math.Abs(weighted-0.6) > 1e-9 {
+		t.Errorf("Got weighted exams score %v; expected 0.6", weighted)
+	}
+	if math.Abs(report.TotalScore-0.6625) > 1e-9 {
+		t.Errorf("Got total score %v; expected 0.6625", report.TotalScore)
+	}
+}
+
+func TestStudentNames(t *testing.T) {
+	student := &Student{
+		Categories: map[string]*Category{
+			"Projects": {Name: "Projects"},
+			"Exams":    {Name: "Exams"},
+			"Homework": {Name: "Homework"},
+		},
+		Assignments: map[string]*Assignment{
+			"Proj 1": {Name: "Proj 1"},
+			"HW 2":   {Name: "HW 2"},
+			"HW 1":   {Name: "HW 1"},
+			"Final":  {Name: "Final"},
+		},
+	}
+	if names := student.CategoryNames(); !reflect.DeepEqual(names, []string{"Exams", "Homework", "Projects"}) {
+		t.Errorf("Got category names %v", names)
+	}
+	if names := student.AssignmentNames(); !reflect.DeepEqual(names, []string{"Final", "HW 1", "HW 2", "Proj 1"}) {
+		t.Errorf("Got assignment names %v", names)
+	}
+}